@@ -49,6 +49,7 @@ func (d *Diffs) add(op int, text string) {
 
 type differ struct {
 	Diffs
+	cfg        *Config
 	checkLines bool
 	deadLine   time.Time
 	bisectV    []int
@@ -62,21 +63,17 @@ type differ struct {
 // If timeout is NoTimeout, or -1, it timeout will be inactive.
 // If it is 0, DefaultTimeout will be used.
 func DiffMain(text1, text2 string, checkLines bool, timeout time.Duration) Diffs {
-	d := new(differ)
-	if timeout != -1 {
-		if timeout == 0 {
-			timeout = DefaultTimeout
-		}
-		d.deadLine = time.Now().Add(timeout)
-	}
-	d.diffMain(text1, text2, checkLines)
-	d.CleanupMerge()
-	return d.Diffs
+	c := &Config{Timeout: timeout, CheckLines: checkLines}
+	return c.DiffMain(text1, text2)
 }
 
 // Find the differences between two texts.  Simplifies the problem by
 // stripping any common prefix or suffix off the texts before diffing.
 func (d *differ) diffMain(text1, text2 string, checkLines bool) {
+	if d.cfg.segmented() {
+		d.diffGraphemes(text1, text2, checkLines)
+		return
+	}
 	if text1 == text2 {
 		if text1 != "" {
 			d.add(Equal, text1)
@@ -173,6 +170,7 @@ func (d *differ) diffLineMode(text1, text2 string) {
 
 	ld := *d
 	ld.Diffs = nil
+	ld.cfg = nil
 	ld.diffMain(b.chars1, b.chars2, false)
 
 	// Convert the diff back to original text.
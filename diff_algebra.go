@@ -0,0 +1,154 @@
+// Diff Match and Patch – inversion, composition and transformation
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+// Return a Diff list transforming text2 into text1, i.e. with
+// insertions and deletions swapped.
+func (diffs Diffs) Invert() Diffs {
+	inv := make(Diffs, len(diffs))
+	for i, d := range diffs {
+		switch d.Op {
+		case Insert:
+			d.Op = Delete
+		case Delete:
+			d.Op = Insert
+		}
+		inv[i] = d
+	}
+	return inv
+}
+
+// Combine a Diff list a transforming text1 into text2, and a list b
+// transforming text2 into text3, into a list transforming text1 into
+// text3. If the text2 sides of a and b differ, a *MismatchError is
+// returned, containing the offset within text2, and the index of
+// the Diff of b not matching.
+func Compose(a, b Diffs) (Diffs, error) {
+	var diffs Diffs
+	ra, rb := diffReader{diffs: a}, diffReader{diffs: b}
+	pos := 0 // position within text2
+	for {
+		opA, textA := ra.peek()
+		opB, textB := rb.peek()
+		switch {
+		case opA == Delete:
+			diffs.add(Delete, textA)
+			ra.skip(len(textA))
+			continue
+		case opB == Insert:
+			diffs.add(Insert, textB)
+			rb.skip(len(textB))
+			continue
+		case opA == noop && opB == noop:
+			diffs.CleanupMerge()
+			return diffs, nil
+		}
+		text, err := rb.match(textA, textB, pos)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case opA == Equal && opB == Equal:
+			diffs.add(Equal, text)
+		case opA == Equal && opB == Delete:
+			diffs.add(Delete, text)
+		case opA == Insert && opB == Equal:
+			diffs.add(Insert, text)
+		}
+		ra.skip(len(text))
+		rb.skip(len(text))
+		pos += len(text)
+	}
+}
+
+// Transform two Diff lists a and b, made concurrently against the same
+// text, into lists a2 and b2, so that applying b, then a2, results
+// in the same text as applying a, then b2. This text contains the
+// changes of both a and b; where both insert text at the same position,
+// the insertion of a comes first. If the text1 sides of a and b differ,
+// a *MismatchError is returned, containing the offset within text1,
+// and the index of the Diff of b not matching.
+func Transform(a, b Diffs) (a2, b2 Diffs, err error) {
+	ra, rb := diffReader{diffs: a}, diffReader{diffs: b}
+	pos := 0 // position within the common text1
+	for {
+		opA, textA := ra.peek()
+		opB, textB := rb.peek()
+		switch {
+		case opA == Insert:
+			a2.add(Insert, textA)
+			b2.add(Equal, textA)
+			ra.skip(len(textA))
+			continue
+		case opB == Insert:
+			a2.add(Equal, textB)
+			b2.add(Insert, textB)
+			rb.skip(len(textB))
+			continue
+		case opA == noop && opB == noop:
+			a2.CleanupMerge()
+			b2.CleanupMerge()
+			return a2, b2, nil
+		}
+		text, err := rb.match(textA, textB, pos)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case opA == Equal && opB == Equal:
+			a2.add(Equal, text)
+			b2.add(Equal, text)
+		case opA == Delete && opB == Equal:
+			a2.add(Delete, text)
+		case opA == Equal && opB == Delete:
+			b2.add(Delete, text)
+		}
+		ra.skip(len(text))
+		rb.skip(len(text))
+		pos += len(text)
+	}
+}
+
+// A diffReader reads a Diff list piecewise.
+type diffReader struct {
+	diffs Diffs
+	i     int // index of the current Diff
+	off   int // offset within the text of the current Diff
+}
+
+// Return the operation and the remaining text of the current Diff,
+// or noop, if the end of the list has been reached.
+func (r *diffReader) peek() (op int, text string) {
+	for r.i < len(r.diffs) {
+		if d := r.diffs[r.i]; r.off < len(d.Text) {
+			return d.Op, d.Text[r.off:]
+		}
+		r.i++
+		r.off = 0
+	}
+	return noop, ""
+}
+
+func (r *diffReader) skip(n int) {
+	r.off += n
+}
+
+// Return the longest common part of textA and textB, which both
+// start at pos, where the current Diff of r is located. Both texts
+// must be equal up to the length of the shorter one.
+func (r *diffReader) match(textA, textB string, pos int) (string, error) {
+	n := min(len(textA), len(textB))
+	if n == 0 || textA[:n] != textB[:n] {
+		off := pos + len(commonPrefix(textA[:n], textB[:n]))
+		i := r.i
+		if len(textB) == 0 {
+			i = -1
+		}
+		return "", &MismatchError{Offset: off, Index: i}
+	}
+	return textA[:n], nil
+}
@@ -0,0 +1,45 @@
+// Diff Match and Patch – line-aligned diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+// A LineDiff is a diff of whole lines, as returned by AlignLines.
+// The deletion and the insertion of changed lines share the Detail,
+// which holds the character-level diffs between them.
+type LineDiff struct {
+	Diff
+	Detail Diffs
+}
+
+// Expand the edits of a character-level diff to whole lines: Lines
+// touched by edits become a deletion of the lines of text1, followed
+// by an insertion of the lines of text2; either may be missing, if
+// there are no such lines. Unchanged lines form equalities.
+func (diffs Diffs) AlignLines() (lines []LineDiff) {
+	for _, b := range diffs.lineBlocks() {
+		if b.equal {
+			lines = append(lines, LineDiff{Diff: b.diffs[0]})
+			continue
+		}
+		if del := b.diffs.Text1(); del != "" {
+			lines = append(lines, LineDiff{Diff{Delete, del}, b.diffs})
+		}
+		if ins := b.diffs.Text2(); ins != "" {
+			lines = append(lines, LineDiff{Diff{Insert, ins}, b.diffs})
+		}
+	}
+	return
+}
+
+// Expand the edits to whole lines, like AlignLines,
+// dropping the character-level details.
+func (pDiffs *Diffs) CleanupLines() (diffs Diffs) {
+	for _, l := range pDiffs.AlignLines() {
+		diffs = append(diffs, l.Diff)
+	}
+	*pDiffs = diffs
+	return
+}
@@ -0,0 +1,104 @@
+// Diff Match and Patch – anchored diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"sort"
+	"strings"
+)
+
+// An Anchor is a pair of positions, byte offsets into text1 and text2,
+// which are to be aligned by a diff.
+type Anchor struct {
+	Pos1, Pos2 int
+}
+
+// Find the differences between two texts, keeping each of the anchors
+// unchanged, similar to git diff --anchored: Anchors occurring exactly
+// once in both texts split them, and the segments in between are
+// compared independently. Other anchors are ignored, as are those that,
+// in the order of their positions in text1, would overlap or cross a
+// previous one.
+func (c *Config) DiffAnchored(text1, text2 string, anchors []string) Diffs {
+	type match struct {
+		Anchor
+		n int
+	}
+	var matches []match
+	for _, a := range anchors {
+		i1, i2 := uniqueIndex(text1, a), uniqueIndex(text2, a)
+		if i1 != -1 && i2 != -1 {
+			matches = append(matches, match{Anchor{i1, i2}, len(a)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Pos1 < matches[j].Pos1
+	})
+
+	var pairs []Anchor
+	end1, end2 := 0, 0
+	for _, m := range matches {
+		if m.Pos1 < end1 || m.Pos2 < end2 {
+			continue
+		}
+		end1, end2 = m.Pos1+m.n, m.Pos2+m.n
+		pairs = append(pairs, m.Anchor, Anchor{end1, end2})
+	}
+	return c.DiffAt(text1, text2, pairs)
+}
+
+// Return the index of the only instance of substr in s,
+// or -1, if there is none, or more than one.
+func uniqueIndex(s, substr string) int {
+	if substr == "" {
+		return -1
+	}
+	i := strings.Index(s, substr)
+	if i == -1 || strings.Contains(s[i+1:], substr) {
+		return -1
+	}
+	return i
+}
+
+// Find the differences between two texts, aligning the positions
+// of each anchor: Both texts are split at the anchors, and the
+// segments in between are compared independently. Anchors must be
+// in increasing order in both texts; those that are not, that are
+// out of range, or that do not fall on boundaries of runes, or of
+// grapheme clusters, if c.Graphemes is set, are ignored.
+func (c *Config) DiffAt(text1, text2 string, anchors []Anchor) (diffs Diffs) {
+	add := func(seg Diffs) {
+		for _, d := range seg {
+			n := len(diffs)
+			switch {
+			case d.Text == "":
+				continue
+			case n > 0 && diffs[n-1].Op == d.Op:
+				diffs[n-1].Text += d.Text
+				continue
+			case n > 1 && d.Op == Delete && diffs[n-1].Op == Insert && diffs[n-2].Op == Delete:
+				diffs[n-2].Text += d.Text
+				continue
+			}
+			diffs = append(diffs, d)
+		}
+	}
+
+	pos1, pos2 := 0, 0
+	for _, a := range anchors {
+		if a.Pos1 < pos1 || a.Pos2 < pos2 || a.Pos1 > len(text1) || a.Pos2 > len(text2) {
+			continue
+		}
+		if !c.boundary(text1, a.Pos1) || !c.boundary(text2, a.Pos2) {
+			continue
+		}
+		add(c.DiffMain(text1[pos1:a.Pos1], text2[pos2:a.Pos2]))
+		pos1, pos2 = a.Pos1, a.Pos2
+	}
+	add(c.DiffMain(text1[pos1:], text2[pos2:]))
+	return
+}
@@ -15,6 +15,10 @@ import (
 
 // Reduce the number of edits by eliminating semantically trivial equalities.
 func (pdiffs *Diffs) CleanupSemantic() (diffs Diffs) {
+	return pdiffs.cleanupSemantic(nil)
+}
+
+func (pdiffs *Diffs) cleanupSemantic(c *Config) (diffs Diffs) {
 	diffs = *pdiffs
 	if len(diffs) == 0 {
 		return
@@ -89,9 +93,9 @@ func (pdiffs *Diffs) CleanupSemantic() (diffs Diffs) {
 
 	// Normalize the diff
 	if changes {
-		diffs.CleanupMerge()
+		diffs.cleanupMerge(c)
 	}
-	diffs.CleanupSemanticLossless()
+	diffs.cleanupSemanticLossless(c)
 
 	// Find any overlaps between deletions and insertions:
 	// 	e.g.: <del>abcxxx</del><ins>xxxdef</ins>
@@ -123,8 +127,8 @@ func (pdiffs *Diffs) CleanupSemantic() (diffs Diffs) {
 
 			deletion := diffs[i-1].Text
 			insertion := d.Text
-			overlap1 := commonOverlap(deletion, insertion)
-			overlap2 := commonOverlap(insertion, deletion)
+			overlap1 := c.commonOverlap(deletion, insertion)
+			overlap2 := c.commonOverlap(insertion, deletion)
 
 			if oLen := len(overlap1); oLen >= len(overlap2) {
 				if 2*oLen >= runeCount(deletion) || 2*oLen >= runeCount(insertion) {
@@ -157,6 +161,10 @@ func (pdiffs *Diffs) CleanupSemantic() (diffs Diffs) {
 // which can be shifted sideways to align the edit to a word boundary.
 //	e.g.: The c<ins>at c</ins>ame. -> The <ins>cat </ins>came.
 func (pDiffs *Diffs) CleanupSemanticLossless() (diffs Diffs) {
+	return pDiffs.cleanupSemanticLossless(nil)
+}
+
+func (pDiffs *Diffs) cleanupSemanticLossless(c *Config) (diffs Diffs) {
 	diffs = *pDiffs
 
 	var best fit
@@ -188,8 +196,8 @@ func (pDiffs *Diffs) CleanupSemanticLossless() (diffs Diffs) {
 			edit:      d.Text,
 			equality2: next.Text,
 		}
-		cur.shiftLeft()
-		best = cur.shiftRight()
+		cur.shiftLeft(c)
+		best = cur.shiftRight(c)
 
 		if prev.Text != best.equality1 {
 			// We have an improvement, save it back to the diff
@@ -217,14 +225,14 @@ type fit struct {
 	score                      int
 }
 
-func (f *fit) calcScore() int {
-	f.score = semanticScore(f.equality1, f.edit) + semanticScore(f.edit, f.equality2)
+func (f *fit) calcScore(s Scorer) int {
+	f.score = s.Score(f.equality1, f.edit) + s.Score(f.edit, f.equality2)
 	return f.score
 }
 
 // shift the edit as far left as possible
-func (f *fit) shiftLeft() {
-	if cs := commonSuffix(f.equality1, f.edit); cs != "" {
+func (f *fit) shiftLeft(c *Config) {
+	if cs := c.commonSuffix(f.equality1, f.edit); cs != "" {
 		n := len(cs)
 		f.equality1 = f.equality1[:len(f.equality1)-n]
 		f.edit = cs + f.edit[:len(f.edit)-n]
@@ -233,14 +241,20 @@ func (f *fit) shiftLeft() {
 }
 
 // step character by character right, looking for the best fit
-func (f *fit) shiftRight() (best fit) {
+func (f *fit) shiftRight(c *Config) (best fit) {
+	s := c.scorer()
 	best = *f
-	best.calcScore()
-	for f.edit != "" && f.equality2 != "" && firstRune(f.edit) == firstRune(f.equality2) {
-		f.equality1 += firstUTF8(f.edit)
-		f.edit = f.edit[1:] + firstUTF8(f.equality2)
-		f.equality2 = f.equality2[1:]
-		f.calcScore()
+	best.calcScore(s)
+	for f.edit != "" && f.equality2 != "" {
+		n := c.firstLen(f.edit)
+		first := f.edit[:n]
+		if first != f.equality2[:c.firstLen(f.equality2)] {
+			break
+		}
+		f.equality1 += first
+		f.edit = f.edit[n:] + first
+		f.equality2 = f.equality2[n:]
+		f.calcScore(s)
 
 		// The >= encourages trailing rather than leading whitespace on edits
 		if f.score >= best.score {
@@ -303,6 +317,10 @@ var (
 
 // Reduce the number of edits by eliminating operationally trivial equalities.
 func (pDiffs *Diffs) CleanupEfficiency(editCost int) (diffs Diffs) {
+	return pDiffs.cleanupEfficiency(nil, editCost)
+}
+
+func (pDiffs *Diffs) cleanupEfficiency(c *Config, editCost int) (diffs Diffs) {
 	diffs = *pDiffs
 	if len(diffs) == 0 {
 		return
@@ -403,7 +421,7 @@ func (pDiffs *Diffs) CleanupEfficiency(editCost int) (diffs Diffs) {
 	}
 
 	if changes {
-		diffs.CleanupMerge()
+		diffs.cleanupMerge(c)
 	}
 	*pDiffs = diffs
 	return
@@ -412,19 +430,25 @@ func (pDiffs *Diffs) CleanupEfficiency(editCost int) (diffs Diffs) {
 // Reorder and merge like edit sections.  Merge equalities.
 // Any edit section can move as long as it doesn't cross an equality.
 func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
+	return pDiffs.cleanupMerge(nil)
+}
+
+func (pDiffs *Diffs) cleanupMerge(c *Config) (diffs Diffs) {
 	var insBuf, delBuf strbuf
 	diffs = append(*pDiffs, Diff{Equal, ""})
-	iw := 0 // write index
 
+	// A common prefix factored out of the first edits may result in
+	// one more Diff than has been read, so the output can't be
+	// written in place.
+	out := make(Diffs, 0, len(diffs)+1)
 	w := func(op int, text string) {
-		diffs[iw] = Diff{op, text}
-		iw++
+		out = append(out, Diff{op, text})
 	}
 	prevEqual := func() (eq *Diff) {
-		if iw == 0 {
+		if len(out) == 0 {
 			return
 		}
-		if p := &diffs[iw-1]; p.Op == Equal {
+		if p := &out[len(out)-1]; p.Op == Equal {
 			eq = p
 		}
 		return
@@ -445,8 +469,8 @@ func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
 			textDel := delBuf.join()
 			if textDel != "" && textIns != "" { // both types
 				// Factor out any common prefixes
-				if pfx := commonPrefix(textIns, textDel); pfx != "" {
-					if iw != 0 {
+				if pfx := c.commonPrefix(textIns, textDel); pfx != "" {
+					if len(out) != 0 {
 						if prev := prevEqual(); prev == nil {
 							panic("Previous diff should have been an equality")
 						} else {
@@ -459,7 +483,7 @@ func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
 					textDel = textDel[len(pfx):]
 				}
 				// Factor out any common suffixies.
-				if sfx := commonSuffix(textIns, textDel); sfx != "" {
+				if sfx := c.commonSuffix(textIns, textDel); sfx != "" {
 					d.Text = sfx + d.Text
 					textIns = textIns[:len(textIns)-len(sfx)]
 					textDel = textDel[:len(textDel)-len(sfx)]
@@ -479,7 +503,7 @@ func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
 			}
 		}
 	}
-	diffs = diffs[:iw]
+	diffs = out
 	if last := len(diffs) - 1; diffs[last].Text == "" {
 		diffs = diffs[:last]
 	}
@@ -498,12 +522,12 @@ func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
 			continue
 		}
 		// This is a single edit surrounded by equalities.
-		if strings.HasSuffix(d.Text, prev.Text) {
+		if strings.HasSuffix(d.Text, prev.Text) && c.boundary(d.Text, len(d.Text)-len(prev.Text)) {
 			diffs[i].Text = prev.Text + d.Text[:len(d.Text)-len(prev.Text)]
 			next.Text = prev.Text + next.Text
 			prev.Op = noop
 			changes = true
-		} else if strings.HasPrefix(d.Text, next.Text) {
+		} else if strings.HasPrefix(d.Text, next.Text) && c.boundary(d.Text, len(next.Text)) {
 			prev.Text += next.Text
 			diffs[i].Text = d.Text[len(next.Text):] + next.Text
 			next.Op = noop
@@ -513,7 +537,7 @@ func (pDiffs *Diffs) CleanupMerge() (diffs Diffs) {
 
 	// If shifts were made, the diff needs reordering and another shift sweep
 	if changes {
-		diffs.CleanupMerge()
+		diffs.cleanupMerge(c)
 	}
 	*pDiffs = diffs
 	return diffs
@@ -0,0 +1,155 @@
+// Diff Match and Patch – diff configuration
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A Config holds the parameters controlling how differences are
+// computed and cleaned up. Its zero value makes the methods behave
+// like DiffMain, with checkLines unset and the default timeout,
+// and like the cleanup methods of Diffs.
+type Config struct {
+	// Timeout limits the time spent on a diff, like the timeout
+	// argument of DiffMain: If it is 0, DefaultTimeout is used;
+	// if it is NoTimeout, the time is not limited.
+	Timeout time.Duration
+
+	// If CheckLines is set, a line-level diff is run first to
+	// identify the changed areas, like DiffMain does if its
+	// checkLines argument is true.
+	CheckLines bool
+
+	// If Graphemes is set, the boundaries of the diffs fall on
+	// boundaries of extended grapheme clusters, as defined by
+	// Unicode Standard Annex #29, so that letters with combining
+	// marks, emoji sequences, flags, Hangul syllables, and CR LF
+	// pairs are never split. The cleanup methods of Config
+	// preserve this property.
+	Graphemes bool
+
+	// If Normalize is set, texts are compared in the form returned
+	// by it, like a Unicode normalization form, which it is applied
+	// to cluster by cluster. The resulting diffs contain the original
+	// texts: Deletions and insertions are those of text1 and text2,
+	// widened to whole extended grapheme clusters. Clusters equal
+	// in normalized form, but written differently, are reported as
+	// a deletion followed by an insertion, so that Text1 and Text2
+	// of the diffs return text1 and text2.
+	Normalize func(string) string
+
+	// Ignore selects differences that are not reported. They are
+	// part of equalities, which contain the text of text1.
+	Ignore Ignore
+
+	// Spans of text1 and text2 matched by the same mask are
+	// considered equal, see DiffMasked.
+	Masks []Mask
+
+	// Scorer rates the positions CleanupSemanticLossless may shift
+	// edits to. If it is nil, DefaultScorer is used.
+	Scorer Scorer
+}
+
+// Find the differences between two texts.
+func (c *Config) DiffMain(text1, text2 string) Diffs {
+	if c.folds() {
+		diffs, _ := c.diffMasked(text1, text2, false)
+		return diffs
+	}
+	d := &differ{cfg: c}
+	if c.Timeout != NoTimeout {
+		timeout := c.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		d.deadLine = time.Now().Add(timeout)
+	}
+	d.diffMain(text1, text2, c.CheckLines)
+	d.cleanupMerge(c)
+	return d.Diffs
+}
+
+// Like Diffs.CleanupMerge, but respecting the configuration.
+func (c *Config) CleanupMerge(diffs *Diffs) Diffs {
+	return diffs.cleanupMerge(c)
+}
+
+// Like Diffs.CleanupSemantic, but respecting the configuration.
+func (c *Config) CleanupSemantic(diffs *Diffs) Diffs {
+	return diffs.cleanupSemantic(c)
+}
+
+// Like Diffs.CleanupSemanticLossless, but respecting the configuration.
+func (c *Config) CleanupSemanticLossless(diffs *Diffs) Diffs {
+	return diffs.cleanupSemanticLossless(c)
+}
+
+// Like Diffs.CleanupEfficiency, but respecting the configuration.
+func (c *Config) CleanupEfficiency(diffs *Diffs, editCost int) Diffs {
+	return diffs.cleanupEfficiency(c, editCost)
+}
+
+// The following methods may be called on a nil *Config,
+// which behaves like the zero Config.
+
+func (c *Config) segmented() bool {
+	return c != nil && c.Graphemes
+}
+
+// Report whether a diff may start or end at position i of s.
+func (c *Config) boundary(s string, i int) bool {
+	if i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		return false
+	}
+	return !c.segmented() || graphemeBoundary(s, i)
+}
+
+// Return the length of the first unit of s, a rune, or
+// a grapheme cluster.
+func (c *Config) firstLen(s string) int {
+	if c.segmented() {
+		return firstGraphemeLen(s)
+	}
+	_, n := utf8.DecodeRuneInString(s)
+	return n
+}
+
+// Like commonPrefix, but ending on a boundary of both texts.
+func (c *Config) commonPrefix(text1, text2 string) string {
+	n := len(commonPrefix(text1, text2))
+	for c.segmented() && n > 0 && !(c.boundary(text1, n) && c.boundary(text2, n)) {
+		n--
+	}
+	return text1[:n]
+}
+
+// Like commonSuffix, but starting on a boundary of both texts.
+func (c *Config) commonSuffix(text1, text2 string) string {
+	n := len(commonSuffix(text1, text2))
+	for c.segmented() && n > 0 && !(c.boundary(text1, len(text1)-n) && c.boundary(text2, len(text2)-n)) {
+		n--
+	}
+	return text1[len(text1)-n:]
+}
+
+// Like commonOverlap, but starting and ending on boundaries.
+func (c *Config) commonOverlap(text1, text2 string) string {
+	best := commonOverlap(text1, text2)
+	if !c.segmented() {
+		return best
+	}
+	for n := len(best); n > 0; n-- {
+		if strings.HasSuffix(text1, text2[:n]) && c.boundary(text1, len(text1)-n) && c.boundary(text2, n) {
+			return text2[:n]
+		}
+	}
+	return ""
+}
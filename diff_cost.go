@@ -0,0 +1,132 @@
+// Diff Match and Patch – cost models
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strconv"
+)
+
+// A CostModel assigns a cost to diffs, like the size of their encoding.
+// Each diff costs the constant of its operation, plus the weight of
+// its text.
+type CostModel struct {
+	Insert   int // cost of an insertion
+	Delete   int // cost of a deletion
+	Equality int // cost of an equality, which breaks a run of edits
+
+	// Weight returns the cost of the text of a diff with operation op.
+	// If it is nil, the text of insertions costs one per rune,
+	// the text of other diffs is free.
+	Weight func(op int, text string) int
+}
+
+// DeltaCost models the length of the diffs encoded by ToDelta.
+var DeltaCost = &CostModel{
+	Insert:   2, // operation, and separator
+	Delete:   2,
+	Equality: 2,
+	Weight: func(op int, text string) int {
+		if op == Insert {
+			return len(encodeURI(text))
+		}
+		return len(strconv.Itoa(UTF16.count(text)))
+	},
+}
+
+// Return a function usable as Weight of a CostModel, which sums
+// the weights of the runes of a text.
+func RuneWeight(weight func(op int, r rune) int) func(op int, text string) int {
+	return func(op int, text string) (sum int) {
+		for _, r := range text {
+			sum += weight(op, r)
+		}
+		return
+	}
+}
+
+// Return the cost of diffs.
+func (m *CostModel) Cost(diffs Diffs) (cost int) {
+	for _, d := range diffs {
+		cost += m.cost(d.Op, d.Text)
+	}
+	return
+}
+
+func (m *CostModel) cost(op int, text string) int {
+	c := 0
+	switch op {
+	case Insert:
+		c = m.Insert
+	case Delete:
+		c = m.Delete
+	case Equal:
+		c = m.Equality
+	}
+	if m.Weight != nil {
+		return c + m.Weight(op, text)
+	}
+	if op == Insert {
+		c += runeCount(text)
+	}
+	return c
+}
+
+// Return the cost of a run of edits, consisting of
+// a deletion, and an insertion, which may be empty.
+func (m *CostModel) editCost(del, ins string) (c int) {
+	if del != "" {
+		c += m.cost(Delete, del)
+	}
+	if ins != "" {
+		c += m.cost(Insert, ins)
+	}
+	return
+}
+
+// Reduce the cost of diffs, according to the cost model m, by merging
+// equalities between edits into them, as long as this lowers the cost.
+func (pDiffs *Diffs) CleanupCost(m *CostModel) (diffs Diffs) {
+	diffs = pDiffs.CleanupMerge()
+
+	// Collect the run of edits starting at diffs[i].
+	run := func(diffs Diffs, i int) (del, ins string, end int) {
+		for end = i; end < len(diffs) && diffs[end].Op != Equal; end++ {
+			if diffs[end].Op == Delete {
+				del += diffs[end].Text
+			} else {
+				ins += diffs[end].Text
+			}
+		}
+		return
+	}
+	for changes := true; changes; {
+		changes = false
+		var out Diffs
+		for i := 0; i < len(diffs); i++ {
+			d := diffs[i]
+			if d.Op == Equal && len(out) != 0 && out[len(out)-1].Op != Equal && i+1 < len(diffs) {
+				start := len(out) - 1
+				for start > 0 && out[start-1].Op != Equal {
+					start--
+				}
+				del1, ins1, _ := run(out, start)
+				del2, ins2, end := run(diffs, i+1)
+				del, ins := del1+d.Text+del2, ins1+d.Text+ins2
+				if m.editCost(del, ins) < m.editCost(del1, ins1)+m.cost(Equal, d.Text)+m.editCost(del2, ins2) {
+					out = append(out[:start], Diff{Delete, del}, Diff{Insert, ins})
+					i = end - 1
+					changes = true
+					continue
+				}
+			}
+			out = append(out, d)
+		}
+		diffs = out
+	}
+	*pDiffs = diffs
+	return
+}
@@ -0,0 +1,113 @@
+// Diff Match and Patch – delta encoding
+// 	Original work: Copyright 2006 Google Inc.
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Crush the diff into an encoded string which describes the operations
+// required to transform text1 into text2.
+//
+//	e.g. =3	-2	+ing  -> Keep 3 chars, delete 2 chars, insert 'ing'.
+//
+// Operations are tab-separated. Inserted text is escaped using %xx
+// notation. As in the other ports, lengths are counted in UTF-16 code
+// units, so that deltas can be exchanged with them.
+func (diffs Diffs) ToDelta() string {
+	var b strings.Builder
+	for i, d := range diffs {
+		if i != 0 {
+			b.WriteByte('\t')
+		}
+		switch d.Op {
+		case Insert:
+			b.WriteByte('+')
+			b.WriteString(encodeURI(d.Text))
+		case Delete:
+			b.WriteByte('-')
+			b.WriteString(strconv.Itoa(UTF16.count(d.Text)))
+		case Equal:
+			b.WriteByte('=')
+			b.WriteString(strconv.Itoa(UTF16.count(d.Text)))
+		}
+	}
+	return b.String()
+}
+
+// Errors returned by FromDelta.
+var (
+	ErrDeltaLength    = errors.New("dmp: delta length does not match source text length")
+	ErrDeltaOperation = errors.New("dmp: invalid operation in delta")
+)
+
+// Given the original text1, and an encoded string which describes the
+// operations required to transform text1 into text2, compute the full diff.
+func FromDelta(text1, delta string) (Diffs, error) {
+	var diffs Diffs
+	pos := 0
+	for _, token := range strings.Split(delta, "\t") {
+		if token == "" {
+			// Blank tokens are ok (from a trailing \t).
+			continue
+		}
+		// Each token begins with a one character parameter which specifies the
+		// operation of this token (delete, insert, equality).
+		param := token[1:]
+		switch op := int(token[0]); op {
+		case Insert:
+			text, err := url.PathUnescape(param)
+			if err != nil {
+				return nil, err
+			}
+			diffs.add(Insert, text)
+		case Delete, Equal:
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return nil, err
+			}
+			if n < 0 {
+				return nil, ErrDeltaOperation
+			}
+			i := pos + UTF16.prefixLen(text1[pos:], n)
+			if UTF16.count(text1[pos:i]) != n {
+				return nil, ErrDeltaLength
+			}
+			diffs.add(op, text1[pos:i])
+			pos = i
+		default:
+			return nil, ErrDeltaOperation
+		}
+	}
+	if pos != len(text1) {
+		return nil, ErrDeltaLength
+	}
+	return diffs, nil
+}
+
+// Escape text the way JavaScript's encodeURI does, except for spaces,
+// which are kept, as in the other ports.
+func encodeURI(text string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			strings.IndexByte(" !#$&'()*+,-./:;=?@_~", c) != -1 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+// Diff Match and Patch – edit script with absolute positions
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+// An Edit locates a Diff within both texts. Start1 and End1 delimit
+// the range of text1 it covers, Start2 and End2 the range of text2.
+// The range of an insertion within text1, and of a deletion within
+// text2, is empty, marking the position where the change took place.
+type Edit struct {
+	Op           int
+	Start1, End1 int
+	Start2, End2 int
+}
+
+// Convert a Diff list into a list of Edits, one for each Diff,
+// measuring positions in the given unit. For Lines, positions
+// are the indices of the lines the ends of each Diff are on.
+func (diffs Diffs) Edits(unit Unit) []Edit {
+	edits := make([]Edit, len(diffs))
+	pos1, pos2 := 0, 0
+	for i, d := range diffs {
+		n := unit.count(d.Text)
+		e := Edit{d.Op, pos1, pos1, pos2, pos2}
+		if d.Op != Insert {
+			e.End1 += n
+		}
+		if d.Op != Delete {
+			e.End2 += n
+		}
+		edits[i] = e
+		pos1, pos2 = e.End1, e.End2
+	}
+	return edits
+}
@@ -0,0 +1,208 @@
+// Diff Match and Patch – diffs of transformed texts
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"sort"
+	"strings"
+)
+
+// A foldedText is a text that has been transformed for comparison,
+// segment by segment. It keeps the offsets of the segments in both
+// the transformed and the original text, so that positions can be
+// mapped back to the original.
+type foldedText struct {
+	orig    string
+	text    string
+	off     []int // start of each segment within text, and len(text)
+	origOff []int // start of each segment within orig, and len(orig)
+
+	// names of the masks matching the segments
+	// starting at these offsets of orig
+	masked map[int]string
+}
+
+// Transform orig using next, which returns the length of the
+// segment starting at offset i of a text, and its transformed form.
+func fold(orig string, next func(text string, i int) (n int, folded string)) *foldedText {
+	f := &foldedText{orig: orig}
+	var b strings.Builder
+	for i := 0; i < len(orig); {
+		n, s := next(orig, i)
+		f.off = append(f.off, b.Len())
+		f.origOff = append(f.origOff, i)
+		b.WriteString(s)
+		i += n
+	}
+	f.text = b.String()
+	f.off = append(f.off, len(f.text))
+	f.origOff = append(f.origOff, len(orig))
+	return f
+}
+
+// Return the index of the first segment starting at offset i of the
+// transformed text, or -1, if i is within a segment.
+func (f *foldedText) segment(i int) int {
+	k := sort.SearchInts(f.off, i)
+	if k == len(f.off) || f.off[k] != i {
+		return -1
+	}
+	return k
+}
+
+// Map offset i of the transformed text to the original text.
+// An offset within a segment is mapped to the start of the segment.
+func (f *foldedText) origPos(i int) int {
+	k := sort.SearchInts(f.off, i)
+	if k == len(f.off) || f.off[k] != i {
+		k--
+	}
+	return f.origOff[k]
+}
+
+// Like origPos, but include the segments transformed to ""
+// at the end of the text.
+func (f *foldedText) origEnd(i int) int {
+	if i == len(f.text) {
+		return len(f.orig)
+	}
+	return f.origPos(i)
+}
+
+// Find the differences between the transformed forms of two texts,
+// and return them in terms of the original texts: Edits are widened
+// to whole segments; equalities, which may differ in the originals,
+// are taken from text1. The transformed texts are compared using c,
+// which must not transform them again.
+//
+// If splitMasked is set, spans matched by the same mask that differ
+// in the originals are not part of equalities, but reported as
+// a deletion followed by an insertion, which are listed in masked.
+// Likewise, other spans are reported as a deletion and an insertion,
+// if split is not nil, and returns true for their originals.
+func (c *Config) diffFolded(f1, f2 *foldedText, splitMasked bool, split func(s1, s2 string) bool) (diffs Diffs, masked []MaskedDiff) {
+	fdiffs := c.DiffMain(f1.text, f2.text)
+
+	// Collect the equalities as ranges of the transformed texts,
+	// shrunk to the outermost offsets that are segment boundaries
+	// in both texts. The edits are the gaps between them.
+	type equality struct{ start1, start2, end1, end2 int }
+	var eqs []equality
+	p1, p2 := 0, 0
+	for _, d := range fdiffs {
+		switch d.Op {
+		case Delete:
+			p1 += len(d.Text)
+		case Insert:
+			p2 += len(d.Text)
+		case Equal:
+			n := len(d.Text)
+			i, j := 0, n
+			for i <= n && (f1.segment(p1+i) == -1 || f2.segment(p2+i) == -1) {
+				i++
+			}
+			for j > i && (f1.segment(p1+j) == -1 || f2.segment(p2+j) == -1) {
+				j--
+			}
+			if i < j {
+				eqs = append(eqs, equality{p1 + i, p2 + i, p1 + j, p2 + j})
+			}
+			p1 += n
+			p2 += n
+		}
+	}
+
+	// Add a deletion and an insertion, merging them
+	// into the edits at the end of diffs.
+	fixed := 0 // diffs before this index are not merged into
+	addEdit := func(del, ins string) {
+		n := len(diffs)
+		for n > fixed && diffs[n-1].Op != Equal {
+			n--
+		}
+		for _, d := range diffs[n:] {
+			if d.Op == Delete {
+				del = d.Text + del
+			} else {
+				ins = d.Text + ins
+			}
+		}
+		diffs = diffs[:n]
+		if del != "" {
+			diffs.add(Delete, del)
+		}
+		if ins != "" {
+			diffs.add(Insert, ins)
+		}
+	}
+
+	// Add the equality starting at offsets q1 and q2 of the
+	// transformed texts, and ending at offset end1 of text1.
+	eq1 := 0 // start of the equality in text1
+	last1, last2 := len(f1.off)-1, len(f2.off)-1
+	equal := func(q1, q2, end1 int) {
+		if splitMasked || split != nil {
+			k1, k2 := f1.segment(q1), f2.segment(q2)
+			for k1 != -1 && k2 != -1 {
+				// Skip segments transformed to "".
+				for k1 < last1 && f1.off[k1+1] == f1.off[k1] {
+					k1++
+				}
+				for k2 < last2 && f2.off[k2+1] == f2.off[k2] {
+					k2++
+				}
+				if k1 == last1 || f1.origOff[k1] >= end1 {
+					break
+				}
+
+				// Find the shortest runs of segments starting at k1,
+				// and k2, that are equal when transformed.
+				j1, j2 := k1+1, k2+1
+				for f1.off[j1]-q1 != f2.off[j2]-q2 {
+					if f1.off[j1]-q1 < f2.off[j2]-q2 {
+						j1++
+					} else {
+						j2++
+					}
+				}
+				s1 := f1.orig[f1.origOff[k1]:f1.origOff[j1]]
+				s2 := f2.orig[f2.origOff[k2]:f2.origOff[j2]]
+				mask, isMasked := f1.masked[f1.origOff[k1]]
+				if s1 != s2 && (isMasked && splitMasked || !isMasked && split != nil && split(s1, s2)) {
+					if f1.origOff[k1] > eq1 {
+						diffs.add(Equal, f1.orig[eq1:f1.origOff[k1]])
+					}
+					if isMasked {
+						masked = append(masked, MaskedDiff{Index: len(diffs), Mask: mask})
+						diffs.add(Delete, s1)
+						diffs.add(Insert, s2)
+						fixed = len(diffs)
+					} else {
+						addEdit(s1, s2)
+					}
+					eq1 = f1.origOff[j1]
+				}
+				k1, k2 = j1, j2
+			}
+		}
+		if end1 > eq1 {
+			diffs.add(Equal, f1.orig[eq1:end1])
+		}
+	}
+
+	pos1, pos2 := 0, 0 // end of the previous equality in text1, and text2
+	eqs = append(eqs, equality{len(f1.text), len(f2.text), len(f1.text), len(f2.text)})
+	for _, e := range eqs {
+		end1 := max(pos1, f1.origPos(e.start1))
+		end2 := max(pos2, f2.origPos(e.start2))
+		addEdit(f1.orig[pos1:end1], f2.orig[pos2:end2])
+		eq1 = end1
+		pos1, pos2 = f1.origEnd(e.end1), f2.origEnd(e.end2)
+		equal(e.start1, e.start2, pos1)
+	}
+	return
+}
@@ -0,0 +1,195 @@
+// Diff Match and Patch – grapheme cluster segmentation
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Values of the Grapheme_Cluster_Break property, see Unicode
+// Standard Annex #29, "Unicode Text Segmentation".
+const (
+	gcbOther = iota
+	gcbCR
+	gcbLF
+	gcbControl
+	gcbExtend
+	gcbZWJ
+	gcbRegionalIndicator
+	gcbPrepend
+	gcbSpacingMark
+	gcbL
+	gcbV
+	gcbT
+	gcbLV
+	gcbLVT
+	gcbExtPict // Extended_Pictographic, an emoji property
+)
+
+// Return the Grapheme_Cluster_Break property of r. It is derived
+// from the general categories known by package unicode, and a few
+// ranges of characters, which is a close approximation of the
+// property defined by the Unicode Character Database.
+func graphemeBreak(r rune) int {
+	switch {
+	case r < 0x7F:
+		switch {
+		case r == '\r':
+			return gcbCR
+		case r == '\n':
+			return gcbLF
+		case r < 0x20:
+			return gcbControl
+		}
+		return gcbOther
+	case r == 0x200D:
+		return gcbZWJ
+	case r == 0x200C, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F,
+		r == 0xFF9E, r == 0xFF9F:
+		// zero width non-joiner, emoji modifiers, tag characters,
+		// halfwidth katakana sound marks
+		return gcbExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gcbRegionalIndicator
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gcbL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gcbV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gcbT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gcbLV
+		}
+		return gcbLVT
+	case r >= 0x0600 && r <= 0x0605, r == 0x06DD, r == 0x070F, r == 0x0890, r == 0x0891,
+		r == 0x08E2, r == 0x0D4E, r == 0x110BD, r == 0x110CD:
+		return gcbPrepend
+	case r == 0x0E33, r == 0x0EB3:
+		return gcbSpacingMark
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return gcbExtend
+	case unicode.Is(unicode.Mc, r):
+		return gcbSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gcbControl
+	case isExtPict(r):
+		return gcbExtPict
+	}
+	return gcbOther
+}
+
+// Report whether r is Extended_Pictographic.
+func isExtPict(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x2199, r == 0x21A9, r == 0x21AA, r == 0x231A, r == 0x231B,
+		r == 0x2328, r == 0x2388, r == 0x23CF, r >= 0x23E9 && r <= 0x23F3,
+		r >= 0x23F8 && r <= 0x23FA, r == 0x24C2, r == 0x25AA, r == 0x25AB, r == 0x25B6,
+		r == 0x25C0, r >= 0x25FB && r <= 0x25FE, r >= 0x2600 && r <= 0x27BF,
+		r == 0x2934, r == 0x2935, r >= 0x2B05 && r <= 0x2B07, r == 0x2B1B, r == 0x2B1C,
+		r == 0x2B50, r == 0x2B55, r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x1FC00 && r <= 0x1FFFD:
+		// regional indicators and emoji modifiers are handled before
+		return true
+	}
+	return false
+}
+
+// Report whether position i of s, which must be the start of a rune,
+// or the end of s, is the boundary of an extended grapheme cluster.
+// The start and the end of s are boundaries.
+func graphemeBoundary(s string, i int) bool {
+	if i == 0 || i == len(s) {
+		return true
+	}
+	r1, n1 := utf8.DecodeLastRuneInString(s[:i])
+	r2, _ := utf8.DecodeRuneInString(s[i:])
+	p1, p2 := graphemeBreak(r1), graphemeBreak(r2)
+
+	switch {
+	case p1 == gcbCR && p2 == gcbLF: // GB3
+		return false
+	case p1 == gcbCR || p1 == gcbLF || p1 == gcbControl: // GB4
+		return true
+	case p2 == gcbCR || p2 == gcbLF || p2 == gcbControl: // GB5
+		return true
+	case p1 == gcbL && (p2 == gcbL || p2 == gcbV || p2 == gcbLV || p2 == gcbLVT): // GB6
+		return false
+	case (p1 == gcbLV || p1 == gcbV) && (p2 == gcbV || p2 == gcbT): // GB7
+		return false
+	case (p1 == gcbLVT || p1 == gcbT) && p2 == gcbT: // GB8
+		return false
+	case p2 == gcbExtend || p2 == gcbZWJ || p2 == gcbSpacingMark: // GB9, GB9a
+		return false
+	case p1 == gcbPrepend: // GB9b
+		return false
+	case p1 == gcbZWJ && p2 == gcbExtPict: // GB11
+		// Look for an Extended_Pictographic, followed by Extend characters.
+		for j := i - n1; j > 0; {
+			r, n := utf8.DecodeLastRuneInString(s[:j])
+			if p := graphemeBreak(r); p != gcbExtend {
+				return p != gcbExtPict
+			}
+			j -= n
+		}
+		return true
+	case p1 == gcbRegionalIndicator && p2 == gcbRegionalIndicator: // GB12, GB13
+		// Only break between pairs of regional indicators.
+		n := 0
+		for j := i; j > 0; n++ {
+			r, size := utf8.DecodeLastRuneInString(s[:j])
+			if graphemeBreak(r) != gcbRegionalIndicator {
+				break
+			}
+			j -= size
+		}
+		return n%2 == 0
+	}
+	return true // GB999
+}
+
+// Return the length of the first extended grapheme cluster of s.
+func firstGraphemeLen(s string) int {
+	for i := range s {
+		if i != 0 && graphemeBoundary(s, i) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Split s into extended grapheme clusters.
+func graphemes(s string) (clusters []string) {
+	for s != "" {
+		n := firstGraphemeLen(s)
+		clusters = append(clusters, s[:n])
+		s = s[n:]
+	}
+	return
+}
+
+// Find the differences between two texts, treating extended grapheme
+// clusters as units. Like lines in line mode, each distinct cluster is
+// represented by a single rune, and the resulting strings are compared.
+func (d *differ) diffGraphemes(text1, text2 string, checkLines bool) {
+	if checkLines && runeCount(text1) > 100 && runeCount(text2) > 100 {
+		d.diffLineMode(text1, text2)
+		return
+	}
+	m := newLineMunger()
+	chars1 := m.tokensToChars(graphemes(text1))
+	chars2 := m.tokensToChars(graphemes(text2))
+
+	gd := differ{deadLine: d.deadLine, bisectV: d.bisectV}
+	gd.diffMain(chars1, chars2, false)
+	d.bisectV = gd.bisectV
+	diffCharsToLines(gd.Diffs, m.lineArray)
+	d.Diffs = append(d.Diffs, gd.Diffs...)
+}
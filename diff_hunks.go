@@ -0,0 +1,137 @@
+// Diff Match and Patch – grouping of diffs into hunks
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+)
+
+// A Hunk is a region of a Diff list containing changes,
+// together with the unchanged context surrounding them.
+// Start1 and Len1 locate the hunk within text1, Start2 and Len2
+// within text2. For Lines, starts are line indices, and lengths
+// are the numbers of lines spanned.
+type Hunk struct {
+	Start1, Len1 int
+	Start2, Len2 int
+	Diffs        Diffs
+}
+
+// Group a Diff list into hunks. Each hunk contains one or more
+// edits, and up to context units of the adjacent equalities.
+// Edits separated by an equality not larger than twice the
+// context are put into the same hunk. For Lines, context is counted
+// in whole lines in addition to the lines containing the edits, as
+// in unified diffs; for Bytes, it is extended so that no rune
+// gets split. A negative context results in a single
+// hunk covering the whole diff, if there are any edits at all.
+func (diffs Diffs) Hunks(context int, unit Unit) (hunks []Hunk) {
+	iLastEdit := -1
+	for i, d := range diffs {
+		if d.Op != Equal {
+			iLastEdit = i
+		}
+	}
+	if iLastEdit == -1 {
+		return
+	}
+
+	var (
+		h          *Hunk
+		pos1, pos2 int    // position of the current diff
+		lead       string // leading context of the next hunk
+		atStart1   = true // is pos1 at the start of a line?
+		atStart2   = true
+	)
+	head := func(text string) string {
+		if context < 0 {
+			return text
+		}
+		n := context
+		if unit == Lines && !(atStart1 && atStart2) {
+			// complete the current line
+			n++
+		}
+		return text[:unit.prefixLen(text, n)]
+	}
+	tail := func(text string) string {
+		if context < 0 {
+			return text
+		}
+		n := context
+		if unit == Lines {
+			n++
+		}
+		return text[len(text)-unit.suffixLen(text, n):]
+	}
+	closeHunk := func() {
+		h.Len1 = hunkLen(h.Diffs.Text1(), unit)
+		h.Len2 = hunkLen(h.Diffs.Text2(), unit)
+		hunks = append(hunks, *h)
+		h = nil
+	}
+
+	for i, d := range diffs {
+		switch {
+		case d.Op != Equal:
+			if h == nil {
+				n := unit.count(lead)
+				h = &Hunk{Start1: pos1 - n, Start2: pos2 - n}
+				if lead != "" {
+					h.Diffs.add(Equal, lead)
+				}
+			}
+			h.Diffs.add(d.Op, d.Text)
+		case h == nil:
+			lead = tail(d.Text)
+		case i > iLastEdit:
+			if text := head(d.Text); text != "" {
+				h.Diffs.add(Equal, text)
+			}
+			closeHunk()
+		default:
+			text1, text2 := head(d.Text), tail(d.Text)
+			if len(text1)+len(text2) >= len(d.Text) {
+				h.Diffs.add(Equal, d.Text)
+				break
+			}
+			if text1 != "" {
+				h.Diffs.add(Equal, text1)
+			}
+			closeHunk()
+			lead = text2
+		}
+
+		if d.Text == "" {
+			continue
+		}
+		n := unit.count(d.Text)
+		atEOL := strings.HasSuffix(d.Text, "\n")
+		if d.Op != Insert {
+			pos1 += n
+			atStart1 = atEOL
+		}
+		if d.Op != Delete {
+			pos2 += n
+			atStart2 = atEOL
+		}
+	}
+	if h != nil {
+		closeHunk()
+	}
+	return
+}
+
+// Compute the length of a hunk's text. For Lines, a final line
+// without a line break is counted too.
+func hunkLen(text string, unit Unit) int {
+	n := unit.count(text)
+	if unit == Lines && text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,159 @@
+// Diff Match and Patch – ignoring differences
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Ignore is a set of flags selecting kinds of differences
+// to be ignored, similar to the options of diff(1).
+type Ignore uint
+
+const (
+	IgnoreCase        Ignore = 1 << iota // differences in case, like -i
+	IgnoreSpaceChange                    // changes in the amount of white space, like -b
+	IgnoreAllSpace                       // white space within lines, like -w
+	IgnoreBlankLines                     // inserted or deleted blank lines, like --ignore-blank-lines
+	IgnoreCR                             // CR LF versus LF line endings
+)
+
+// Find the differences between the lines of two texts. Unlike DiffMain
+// does with CheckLines set, only whole lines are compared; Normalize,
+// Ignore, and Masks are applied to them.
+func (c *Config) DiffLines(text1, text2 string) Diffs {
+	m := newLineMunger()
+	lines := func(text string) *foldedText {
+		segment := c.segmenter(text, nil)
+		return fold(text, func(text string, i int) (int, string) {
+			n := strings.IndexByte(text[i:], '\n') + 1
+			if n == 0 {
+				n = len(text) - i
+			}
+			var key strings.Builder
+			for j := i; j < i+n; {
+				k, s := segment(text, j)
+				key.WriteString(s)
+				j += k
+			}
+			if key.Len() == 0 {
+				// an ignored blank line
+				return n, ""
+			}
+			return n, m.tokensToChars([]string{key.String()})
+		})
+	}
+	plain := &Config{Timeout: c.Timeout}
+	diffs, _ := plain.diffFolded(lines(text1), lines(text2), false, c.splitNormalized())
+	return diffs
+}
+
+// Return a function reporting whether the originals of two spans, which
+// are equal when transformed, differ in more than what c.Ignore and c.Masks
+// ignore, so that they must be reported as a deletion and an insertion.
+// If c.Normalize is nil, nil is returned.
+func (c *Config) splitNormalized() func(s1, s2 string) bool {
+	if c.Normalize == nil {
+		return nil
+	}
+	ignore := *c
+	ignore.Normalize = nil
+	return func(s1, s2 string) bool {
+		return ignore.foldString(s1) != ignore.foldString(s2)
+	}
+}
+
+// Return the form in which text is compared.
+func (c *Config) foldString(text string) string {
+	segment := c.segmenter(text, nil)
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		n, s := segment(text, i)
+		b.WriteString(s)
+		i += n
+	}
+	return b.String()
+}
+
+// Return the length of the segment starting at offset i of text, and
+// the form in which it is compared, according to c.Normalize and c.Ignore.
+// Segments are extended grapheme clusters, runs of white space, and
+// blank lines.
+func (c *Config) foldSegment(text string, i int) (int, string) {
+	s := text[i:]
+	if c.Ignore&IgnoreBlankLines != 0 && (i == 0 || text[i-1] == '\n') {
+		if n := blankLineLen(s); n > 0 {
+			return n, ""
+		}
+	}
+	if c.Ignore&(IgnoreSpaceChange|IgnoreAllSpace) != 0 {
+		if n := spaceLen(s); n > 0 {
+			if c.Ignore&IgnoreAllSpace != 0 || atLineEnd(s[n:]) {
+				return n, ""
+			}
+			return n, " "
+		}
+	}
+	n := firstGraphemeLen(s)
+	t := s[:n]
+	if c.Ignore&IgnoreCR != 0 && t == "\r\n" {
+		return n, "\n"
+	}
+	if c.Normalize != nil {
+		t = c.Normalize(t)
+	}
+	if c.Ignore&IgnoreCase != 0 {
+		t = foldCase(t)
+	}
+	return n, t
+}
+
+// Return the length of the white space at the start of s,
+// not including line breaks.
+func spaceLen(s string) int {
+	for i, r := range s {
+		if r == '\r' || r == '\n' || !unicode.IsSpace(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// Report whether s starts with a line break, or is empty.
+func atLineEnd(s string) bool {
+	return s == "" || s[0] == '\n' || strings.HasPrefix(s, "\r\n")
+}
+
+// Return the length of the blank line at the start of s,
+// including its line break, or 0, if there is none.
+func blankLineLen(s string) int {
+	n := spaceLen(s)
+	switch {
+	case strings.HasPrefix(s[n:], "\n"):
+		return n + 1
+	case strings.HasPrefix(s[n:], "\r\n"):
+		return n + 2
+	case n == len(s):
+		return n
+	}
+	return 0
+}
+
+// Map each rune of s to the smallest rune it is equal to under
+// simple case folding.
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		m := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < m {
+				m = f
+			}
+		}
+		return m
+	}, s)
+}
@@ -0,0 +1,275 @@
+// Diff Match and Patch – indent heuristic for line diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"sort"
+	"strings"
+)
+
+// Shift insertions and deletions of whole lines, which are surrounded
+// by equalities, to the positions where they are easiest to read,
+// using the indent heuristic of git: The lines around the candidate
+// positions are rated by their indentation, and the blank lines nearby.
+// This is meant for diffs of lines, like those of Config.DiffLines;
+// edits of parts of lines, and edits next to equalities that start or
+// end within lines, are left alone.
+func (pDiffs *Diffs) CleanupIndentHeuristic() (diffs Diffs) {
+	diffs = *pDiffs
+	text1, text2 := diffs.Text1(), diffs.Text2()
+	lines1, lines2 := splitLines(text1), splitLines(text2)
+	starts1, starts2 := lineStarts(lines1), lineStarts(lines2)
+
+	p1, p2 := 0, 0 // offset of the current diff in text1, and text2
+	for i, d := range diffs {
+		if d.Op != Equal && d.Text != "" && i > 0 && i+1 < len(diffs) &&
+			diffs[i-1].Op == Equal && diffs[i+1].Op == Equal && wholeLines(d.Text) {
+
+			text, lines, starts, pos := text1, lines1, starts1, p1
+			if d.Op == Insert {
+				text, lines, starts, pos = text2, lines2, starts2, p2
+			}
+			prev, next := diffs[i-1].Text, diffs[i+1].Text
+			eqStart := lineIndex(text, starts, pos-len(prev))
+			start := lineIndex(text, starts, pos)
+			eqEnd := lineIndex(text, starts, pos+len(d.Text)+len(next))
+			if eqStart != -1 && start != -1 && eqEnd != -1 {
+				n := len(splitLines(d.Text))
+				if s := slideLines(lines, eqStart, start, n, eqEnd); s != start {
+					diffs[i-1].Text = strings.Join(lines[eqStart:s], "")
+					diffs[i].Text = strings.Join(lines[s:s+n], "")
+					diffs[i+1].Text = strings.Join(lines[s+n:eqEnd], "")
+					p1 += len(diffs[i-1].Text) - len(prev)
+					p2 += len(diffs[i-1].Text) - len(prev)
+				}
+			}
+		}
+		switch diffs[i].Op {
+		case Equal:
+			p1 += len(diffs[i].Text)
+			p2 += len(diffs[i].Text)
+		case Delete:
+			p1 += len(diffs[i].Text)
+		case Insert:
+			p2 += len(diffs[i].Text)
+		}
+	}
+
+	// Remove equalities that have become empty, and join the edits around them.
+	out := diffs[:0]
+	for _, d := range diffs {
+		switch {
+		case d.Text == "":
+			continue
+		case len(out) > 0 && out[len(out)-1].Op == d.Op:
+			out[len(out)-1].Text += d.Text
+			continue
+		case len(out) > 1 && d.Op == Delete && out[len(out)-1].Op == Insert && out[len(out)-2].Op == Delete:
+			out[len(out)-2].Text += d.Text
+			continue
+		}
+		out = append(out, d)
+	}
+	*pDiffs = out
+	return out
+}
+
+func wholeLines(text string) bool {
+	return text == "" || strings.HasSuffix(text, "\n")
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Return the offsets of lines within their text, and the length of the text.
+func lineStarts(lines []string) []int {
+	starts := make([]int, 0, len(lines)+1)
+	off := 0
+	for _, line := range lines {
+		starts = append(starts, off)
+		off += len(line)
+	}
+	return append(starts, off)
+}
+
+// Return the index of the line starting at offset i of text, len(lines),
+// if i is the end of text, or -1, if i is not on a line boundary.
+func lineIndex(text string, starts []int, i int) int {
+	if i > 0 && i < len(text) && text[i-1] != '\n' {
+		return -1
+	}
+	return sort.SearchInts(starts, i)
+}
+
+// Parameters of the indent heuristic, as determined by git's authors.
+const (
+	maxBlanks = 20 // blank lines exceeding this number are not counted
+
+	startOfFilePenalty              = 1
+	endOfFilePenalty                = 21
+	totalBlankWeight                = -30
+	postBlankWeight                 = 6
+	relativeIndentPenalty           = -4
+	relativeIndentWithBlankPenalty  = 10
+	relativeOutdentPenalty          = 24
+	relativeOutdentWithBlankPenalty = 17
+	relativeDedentPenalty           = 23
+	relativeDedentWithBlankPenalty  = 17
+	indentWeight                    = 60
+	maxSliding                      = 100
+)
+
+// Slide the group of n lines starting at lines[start] within the range
+// of lines[eqStart:eqEnd], and return its best start.
+func slideLines(lines []string, eqStart, start, n, eqEnd int) int {
+	end := start + n
+	for start > eqStart && lines[start-1] == lines[end-1] {
+		start--
+		end--
+	}
+	earliestEnd := end
+	for end < eqEnd && lines[start] == lines[end] {
+		start++
+		end++
+	}
+	if end == earliestEnd {
+		return start
+	}
+
+	shift := max(earliestEnd, max(end-n-1, end-maxSliding))
+	bestShift := -1
+	var best splitScore
+	for ; shift <= end; shift++ {
+		var s splitScore
+		s.add(measureSplit(lines, shift))
+		s.add(measureSplit(lines, shift-n))
+		if bestShift == -1 || s.cmp(best) <= 0 {
+			best = s
+			bestShift = shift
+		}
+	}
+	return bestShift - n
+}
+
+// The properties of the lines around a split, before lines[split].
+type splitMeasurement struct {
+	endOfFile  bool
+	indent     int // of the line following the split, -1 if blank
+	preBlank   int // number of blank lines before the split
+	preIndent  int // of the nearest non-blank line before the split, -1 if none
+	postBlank  int // number of blank lines after the line following the split
+	postIndent int // of the nearest non-blank line after the line following the split, -1 if none
+}
+
+func measureSplit(lines []string, split int) (m splitMeasurement) {
+	if split >= len(lines) {
+		m.endOfFile = true
+		m.indent = -1
+	} else {
+		m.indent = lineIndent(lines[split])
+	}
+	m.preIndent = -1
+	for i := split - 1; i >= 0; i-- {
+		if m.preIndent = lineIndent(lines[i]); m.preIndent != -1 {
+			break
+		}
+		if m.preBlank++; m.preBlank == maxBlanks {
+			m.preIndent = 0
+			break
+		}
+	}
+	m.postIndent = -1
+	for i := split + 1; i < len(lines); i++ {
+		if m.postIndent = lineIndent(lines[i]); m.postIndent != -1 {
+			break
+		}
+		if m.postBlank++; m.postBlank == maxBlanks {
+			m.postIndent = 0
+			break
+		}
+	}
+	return
+}
+
+// Return the indentation of line, or -1, if it is blank.
+func lineIndent(line string) int {
+	indent, rest := indentation(line)
+	if strings.TrimSpace(rest) == "" {
+		return -1
+	}
+	return indent
+}
+
+type splitScore struct {
+	effectiveIndent int
+	penalty         int
+}
+
+func (s *splitScore) add(m splitMeasurement) {
+	if m.preIndent == -1 && m.preBlank == 0 {
+		s.penalty += startOfFilePenalty
+	}
+	if m.endOfFile {
+		s.penalty += endOfFilePenalty
+	}
+	postBlank := 0
+	if m.indent == -1 {
+		postBlank = 1 + m.postBlank
+	}
+	totalBlank := m.preBlank + postBlank
+	s.penalty += totalBlankWeight * totalBlank
+	s.penalty += postBlankWeight * postBlank
+
+	indent := m.indent
+	if indent == -1 {
+		indent = m.postIndent
+	}
+	anyBlanks := totalBlank != 0
+	s.effectiveIndent += indent
+
+	switch {
+	case indent == -1, m.preIndent == -1, indent == m.preIndent:
+	case indent > m.preIndent:
+		if anyBlanks {
+			s.penalty += relativeIndentWithBlankPenalty
+		} else {
+			s.penalty += relativeIndentPenalty
+		}
+	case m.postIndent != -1 && m.postIndent > indent:
+		if anyBlanks {
+			s.penalty += relativeOutdentWithBlankPenalty
+		} else {
+			s.penalty += relativeOutdentPenalty
+		}
+	default:
+		if anyBlanks {
+			s.penalty += relativeDedentWithBlankPenalty
+		} else {
+			s.penalty += relativeDedentPenalty
+		}
+	}
+}
+
+// Compare two scores; a negative result means s is better than s2.
+func (s splitScore) cmp(s2 splitScore) int {
+	c := 0
+	switch {
+	case s.effectiveIndent > s2.effectiveIndent:
+		c = 1
+	case s.effectiveIndent < s2.effectiveIndent:
+		c = -1
+	}
+	return indentWeight*c + s.penalty - s2.penalty
+}
@@ -56,30 +56,52 @@ func newLineMunger() *lineMunger {
 // Returns encoded string.
 func (m *lineMunger) linesToChars(text string) string {
 	lines := strings.SplitAfter(text, "\n")
-	chars := bytes.NewBuffer(make([]byte, 0, 2*len(lines)))
-	for _, line := range lines {
-		if len(line) == 0 {
-			continue
-		}
-		if id, ok := m.lineHash[line]; ok {
-			chars.WriteRune(rune(id))
+	if n := len(lines); lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	return m.tokensToChars(lines)
+}
+
+// Reduce a list of tokens to a string of hashes where each
+// Unicode character represents one token.
+func (m *lineMunger) tokensToChars(tokens []string) string {
+	chars := bytes.NewBuffer(make([]byte, 0, 2*len(tokens)))
+	for _, token := range tokens {
+		if id, ok := m.lineHash[token]; ok {
+			chars.WriteRune(idRune(id))
 		} else {
-			m.lineArray = append(m.lineArray, line)
+			m.lineArray = append(m.lineArray, token)
 			id = len(m.lineArray) - 1
-			m.lineHash[line] = id
-			chars.WriteRune(rune(id))
+			m.lineHash[token] = id
+			chars.WriteRune(idRune(id))
 		}
 	}
 	return chars.String()
 }
 
+// Return the character representing the string with the given id.
+// Surrogate halves are skipped, since they can not be encoded in UTF-8.
+func idRune(id int) rune {
+	if id >= 0xD800 {
+		id += 0x800
+	}
+	return rune(id)
+}
+
+func runeID(r rune) int {
+	if r >= 0xE000 {
+		return int(r) - 0x800
+	}
+	return int(r)
+}
+
 // Rehydrate the text in a diff from a string of line hashes to
 // real lines of text.
 func diffCharsToLines(diffs []Diff, lines []string) {
 	var b bytes.Buffer
 	for i := range diffs {
 		for _, r := range diffs[i].Text {
-			b.WriteString(lines[r])
+			b.WriteString(lines[runeID(r)])
 		}
 		diffs[i].Text = b.String()
 		b.Reset()
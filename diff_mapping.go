@@ -0,0 +1,231 @@
+// Diff Match and Patch – mapping of positions between texts
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"sort"
+)
+
+// A Bias decides where a position ends up that is not
+// unambiguously located in the other text: a position
+// at an insertion point, or within a replaced region.
+type Bias int
+
+const (
+	BiasRight Bias = iota // move behind inserted text, as XIndex does
+	BiasLeft              // stay in front of inserted text
+)
+
+// Map a position within text1 to the equivalent position within text2,
+// measured in the given unit. At an insertion point, the bias decides
+// whether the position is moved behind the inserted text or not.
+// A position within a deleted region is moved to the start of the
+// text replacing it if bias is BiasLeft, or to its end, if bias is
+// BiasRight. The start of a deleted region always maps to the start,
+// the end always to the end of the replacing text.
+//
+// Unlike XIndex, which maps byte offsets, and moves positions within
+// deletions to the start of the deleted region, MapIndex allows to
+// choose a unit and the direction of the bias.
+func (diffs Diffs) MapIndex(loc1 int, unit Unit, bias Bias) (loc2 int) {
+	loc2, _ = diffs.mapIndex(loc1, unit, bias, false)
+	return
+}
+
+// Map a position within text2 to the equivalent position within text1,
+// the same way MapIndex maps in the opposite direction.
+func (diffs Diffs) MapIndexReverse(loc2 int, unit Unit, bias Bias) (loc1 int) {
+	loc1, _ = diffs.mapIndex(loc2, unit, bias, true)
+	return
+}
+
+// Map a range [start, end) within text1 to the equivalent range within
+// text2. The biases of both ends can be chosen independently: to keep
+// text inserted at the edges of the range outside of it, use BiasRight
+// for the start, and BiasLeft for the end. If the range has been
+// deleted completely, the resulting range is empty.
+func (diffs Diffs) MapRange(start, end int, unit Unit, startBias, endBias Bias) (int, int) {
+	return diffs.mapRange(start, end, unit, startBias, endBias, false)
+}
+
+// Map a range within text2 to the equivalent range within text1,
+// the same way MapRange maps in the opposite direction.
+func (diffs Diffs) MapRangeReverse(start, end int, unit Unit, startBias, endBias Bias) (int, int) {
+	return diffs.mapRange(start, end, unit, startBias, endBias, true)
+}
+
+func (diffs Diffs) mapRange(start, end int, unit Unit, startBias, endBias Bias, reverse bool) (int, int) {
+	start, _ = diffs.mapIndex(start, unit, startBias, reverse)
+	end, _ = diffs.mapIndex(end, unit, endBias, reverse)
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// Map a position from the source to the destination text – from text1
+// to text2, or the other way round, if reverse is set. The result
+// deleted reports whether the unit at the position has been deleted
+// from the source text.
+func (diffs Diffs) mapIndex(loc int, unit Unit, bias Bias, reverse bool) (mapped int, deleted bool) {
+	var r mapRun
+	for i := 0; i < len(diffs); {
+		r, i = diffs.nextRun(i, unit, reverse, r.end1(), r.end2())
+		if mapped, deleted, ok := r.resolve(loc, bias); ok {
+			return mapped, deleted
+		}
+	}
+	return r.end2() + loc - r.end1(), false
+}
+
+// A mapRun is either an equality, or a run of deletions and
+// insertions between two equalities. It starts at pos1 in the source
+// text, and at pos2 in the destination text; n1 and n2 are its lengths
+// in both texts.
+type mapRun struct {
+	equal      bool
+	pos1, pos2 int
+	n1, n2     int
+}
+
+func (r *mapRun) end1() int { return r.pos1 + r.n1 }
+func (r *mapRun) end2() int { return r.pos2 + r.n2 }
+
+// Return the run starting at diffs[i], located at pos1 and pos2,
+// and the index of the diff following it.
+func (diffs Diffs) nextRun(i int, unit Unit, reverse bool, pos1, pos2 int) (r mapRun, next int) {
+	r.pos1, r.pos2 = pos1, pos2
+	if d := diffs[i]; d.Op == Equal {
+		r.equal = true
+		r.n1 = unit.count(d.Text)
+		r.n2 = r.n1
+		return r, i + 1
+	}
+	var opDel, opIns int = Delete, Insert
+	if reverse {
+		opDel, opIns = Insert, Delete
+	}
+	for ; i < len(diffs) && diffs[i].Op != Equal; i++ {
+		switch d := diffs[i]; d.Op {
+		case opDel:
+			r.n1 += unit.count(d.Text)
+		case opIns:
+			r.n2 += unit.count(d.Text)
+		}
+	}
+	return r, i
+}
+
+// Map loc, if it is located within the run; ok reports whether it is.
+func (r *mapRun) resolve(loc int, bias Bias) (mapped int, deleted, ok bool) {
+	switch {
+	case r.equal:
+		if loc < r.end1() {
+			return r.pos2 + loc - r.pos1, false, true
+		}
+	case loc == r.pos1 && r.n1 != 0:
+		return r.pos2, true, true
+	case loc == r.pos1 && bias == BiasLeft:
+		return r.pos2, false, true
+	case loc < r.end1():
+		if bias == BiasLeft {
+			return r.pos2, true, true
+		}
+		return r.end2(), true, true
+	}
+	return 0, false, false
+}
+
+// A Mapper maps positions the way MapIndex does, but is meant for
+// mapping many positions using the same Diff list: It is prepared
+// once, in time linear to the length of the list, and then maps a single
+// position in logarithmic time, or a sorted list of positions in
+// one linear sweep.
+type Mapper struct {
+	runs []mapRun
+}
+
+// A Mapping is the result of mapping a position.
+type Mapping struct {
+	Loc     int
+	Deleted bool // the unit at the original position has been deleted
+}
+
+// Create a Mapper that maps positions in the given unit from text1
+// to text2.
+func NewMapper(diffs Diffs, unit Unit) *Mapper {
+	return newMapper(diffs, unit, false)
+}
+
+// Create a Mapper that maps positions in the given unit from text2
+// to text1.
+func NewReverseMapper(diffs Diffs, unit Unit) *Mapper {
+	return newMapper(diffs, unit, true)
+}
+
+func newMapper(diffs Diffs, unit Unit, reverse bool) *Mapper {
+	m := new(Mapper)
+	var r mapRun
+	for i := 0; i < len(diffs); {
+		r, i = diffs.nextRun(i, unit, reverse, r.end1(), r.end2())
+		m.runs = append(m.runs, r)
+	}
+	return m
+}
+
+// Map a single position.
+func (m *Mapper) Map(loc int, bias Bias) Mapping {
+	runs := m.runs
+	k := sort.Search(len(runs), func(i int) bool {
+		return runs[i].end1() > loc
+	})
+	if bias == BiasLeft && k > 0 {
+		// an insertion point at loc, skipped by the search
+		if r := &runs[k-1]; !r.equal && r.n1 == 0 && r.pos1 == loc {
+			k--
+		}
+	}
+	return m.resolve(k, loc, bias)
+}
+
+// Map a list of positions sorted in ascending order. Positions
+// breaking the order are mapped individually using Map.
+func (m *Mapper) MapSorted(locs []int, bias Bias) []Mapping {
+	result := make([]Mapping, len(locs))
+	k := 0
+	swept := 0 // largest position swept so far
+	for i, loc := range locs {
+		if loc < swept {
+			result[i] = m.Map(loc, bias)
+			continue
+		}
+		swept = loc
+		for ; k < len(m.runs); k++ {
+			if _, _, ok := m.runs[k].resolve(loc, bias); ok {
+				break
+			}
+		}
+		result[i] = m.resolve(k, loc, bias)
+	}
+	return result
+}
+
+// Map loc using the k-th run, or, if k is beyond the last run,
+// the end of the texts.
+func (m *Mapper) resolve(k, loc int, bias Bias) (mp Mapping) {
+	if k < len(m.runs) {
+		mp.Loc, mp.Deleted, _ = m.runs[k].resolve(loc, bias)
+		return
+	}
+	if n := len(m.runs); n != 0 {
+		r := &m.runs[n-1]
+		mp.Loc = r.end2() + loc - r.end1()
+	} else {
+		mp.Loc = loc
+	}
+	return
+}
@@ -0,0 +1,120 @@
+// Diff Match and Patch – masking of volatile text
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// A Mask describes spans of text, like time stamps, or UUIDs, whose
+// differences are ignored: A span of text1 matched by Regexp is
+// considered equal to the span at the corresponding position of text2,
+// if the same Mask matches it.
+type Mask struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// A MaskedDiff is a difference of spans matched by the same Mask.
+type MaskedDiff struct {
+	Index int    // of the deletion of the span of text1, followed by the insertion of the span of text2
+	Mask  string // the name of the Mask
+}
+
+// Find the differences between two texts, like DiffMain does. Differences
+// of spans matched by the same mask of c.Masks are reported as a deletion
+// followed by an insertion, so that the diffs contain the real text of
+// both sides; these are listed in masked. Differences ignored due
+// to Ignore are part of equalities.
+func (c *Config) DiffMasked(text1, text2 string) (diffs Diffs, masked []MaskedDiff) {
+	return c.diffMasked(text1, text2, true)
+}
+
+func (c *Config) diffMasked(text1, text2 string, splitMasked bool) (Diffs, []MaskedDiff) {
+	m1, m2 := make(map[int]string), make(map[int]string)
+	f1 := fold(text1, c.segmenter(text1, m1))
+	f2 := fold(text2, c.segmenter(text2, m2))
+	f1.masked, f2.masked = m1, m2
+	plain := *c
+	plain.Normalize = nil
+	plain.Ignore = 0
+	plain.Masks = nil
+	return plain.diffFolded(f1, f2, splitMasked, c.splitNormalized())
+}
+
+// Report whether texts are transformed before being compared.
+func (c *Config) folds() bool {
+	return c.Normalize != nil || c.Ignore != 0 || len(c.Masks) != 0
+}
+
+// Code points of the Supplementary Private Use Areas represent the
+// spans matched by masks, starting at maskRune. Runes of the texts from
+// maskRune on are prefixed by escRune, which is escaped the same way,
+// so that they can not be confused with the placeholders.
+const (
+	maskRune = 0xF0000
+	escRune  = unicode.MaxRune
+)
+
+// Escape the runes of s from maskRune on.
+func escapeMaskRunes(s string) string {
+	var b strings.Builder
+	last := 0 // end of the part of s written to b
+	for i, r := range s {
+		if r >= maskRune {
+			b.WriteString(s[last:i])
+			b.WriteRune(escRune)
+			last = i
+		}
+	}
+	if b.Len() == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Return the function used to fold text: It transforms the spans matched
+// by c.Masks into placeholders, and other segments using foldSegment.
+// Matches overlapping preceding ones are skipped. If masked is not nil,
+// the names of the masks are stored in it, by the start of their spans.
+func (c *Config) segmenter(text string, masked map[int]string) func(text string, i int) (int, string) {
+	if len(c.Masks) == 0 {
+		return c.foldSegment
+	}
+	var matches [][3]int // start, end, and index of the mask
+	for k, m := range c.Masks {
+		for _, loc := range m.Regexp.FindAllStringIndex(text, -1) {
+			if loc[0] < loc[1] {
+				matches = append(matches, [3]int{loc[0], loc[1], k})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i][0] != matches[j][0] {
+			return matches[i][0] < matches[j][0]
+		}
+		return matches[i][2] < matches[j][2]
+	})
+	return func(text string, i int) (int, string) {
+		for len(matches) != 0 && matches[0][0] < i {
+			matches = matches[1:]
+		}
+		if len(matches) != 0 && matches[0][0] == i {
+			m := matches[0]
+			if masked != nil {
+				masked[i] = c.Masks[m[2]].Name
+			}
+			return m[1] - i, string(rune(maskRune + m[2]))
+		}
+		n, s := c.foldSegment(text, i)
+		return n, escapeMaskRunes(s)
+	}
+}
@@ -0,0 +1,147 @@
+// Diff Match and Patch – detection of moved text
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"sort"
+)
+
+// A Move links the deletion of a text to the insertion
+// of the same, or a similar, text elsewhere.
+type Move struct {
+	From     int // index of the deletion within the diffs
+	To       int // index of the insertion
+	Distance int // Levenshtein distance between their texts
+}
+
+// Find deletions and insertions of at least minRunes runes each, whose
+// texts are similar, so that they can be shown as moves: The Levenshtein
+// distance of the texts, relative to the length of the longer one, must
+// not exceed threshold; 0 means the texts must be equal. Deletions and
+// insertions that are adjacent, without an equality between them,
+// replace each other, and are not considered moves. Each diff is part
+// of one move at most; the most similar pairs are preferred.
+// The moves are returned sorted by From.
+//
+// Each deletion is compared with each insertion. A comparison takes
+// time proportional to the length of the texts, multiplied by the
+// distance allowed, as it stops once the distance exceeds it.
+func (diffs Diffs) DetectMoves(minRunes int, threshold float64) (moves []Move) {
+	var dels, ins []int
+	runes := make(map[int][]rune)
+	for i, d := range diffs {
+		if d.Op == Equal || d.Text == "" || runeCount(d.Text) < minRunes {
+			continue
+		}
+		if d.Op == Delete {
+			dels = append(dels, i)
+		} else {
+			ins = append(ins, i)
+		}
+		runes[i] = []rune(d.Text)
+	}
+
+	type candidate struct {
+		Move
+		ratio float64
+	}
+	var cands []candidate
+	for _, i := range dels {
+		for _, j := range ins {
+			if diffs.adjacentEdits(i, j) {
+				continue
+			}
+			r1, r2 := runes[i], runes[j]
+			n1, n2 := len(r1), len(r2)
+			limit := threshold * float64(max(n1, n2))
+			if d := n1 - n2; float64(d) > limit || float64(-d) > limit {
+				// The distance is at least the difference of the lengths.
+				continue
+			}
+			if dist := levenshtein(r1, r2, int(limit)); dist <= int(limit) {
+				cands = append(cands, candidate{Move{i, j, dist}, float64(dist) / float64(max(n1, n2))})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(a, b int) bool {
+		return cands[a].ratio < cands[b].ratio
+	})
+
+	used := make(map[int]bool)
+	for _, c := range cands {
+		if !used[c.From] && !used[c.To] {
+			used[c.From], used[c.To] = true, true
+			moves = append(moves, c.Move)
+		}
+	}
+	sort.Slice(moves, func(a, b int) bool {
+		return moves[a].From < moves[b].From
+	})
+	return
+}
+
+// Return the Levenshtein distance between r1 and r2, or limit+1, as
+// soon as it is known to exceed limit. Only the cells of the matrix
+// within limit of its diagonal are computed; the difference of the
+// lengths must not exceed limit.
+func levenshtein(r1, r2 []rune, limit int) int {
+	inf := limit + 1
+	prev := make([]int, len(r2)+1) // the row of the previous rune of r1
+	cur := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = min(j, inf)
+	}
+	for i := 1; i <= len(r1); i++ {
+		lo, hi := max(1, i-limit), min(len(r2), i+limit)
+		cur[lo-1] = inf
+		if lo == 1 {
+			cur[0] = min(i, inf)
+		}
+		rowMin := cur[lo-1]
+		for j := lo; j <= hi; j++ {
+			d := prev[j-1]
+			if r1[i-1] != r2[j-1] {
+				d++
+			}
+			d = min(d, min(prev[j], cur[j-1])+1)
+			cur[j] = min(d, inf)
+			rowMin = min(rowMin, cur[j])
+		}
+		if hi < len(r2) {
+			cur[hi+1] = inf
+		}
+		if rowMin > limit {
+			return inf
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(r2)]
+}
+
+// Report whether there is no equality between diffs[i] and diffs[j].
+func (diffs Diffs) adjacentEdits(i, j int) bool {
+	if i > j {
+		i, j = j, i
+	}
+	for k := i + 1; k < j; k++ {
+		if diffs[k].Op == Equal {
+			return false
+		}
+	}
+	return true
+}
+
+// Map the indices of the diffs that are part of moves
+// to the indices of the moves.
+func movedDiffs(moves []Move) map[int]int {
+	m := make(map[int]int, 2*len(moves))
+	for k, mv := range moves {
+		m[mv.From] = k
+		m[mv.To] = k
+	}
+	return m
+}
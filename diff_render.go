@@ -0,0 +1,192 @@
+// Diff Match and Patch – configurable renderers
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"errors"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Renderer writes a report of a Diff list to w.
+type Renderer interface {
+	Render(w io.Writer, diffs Diffs) error
+}
+
+// ErrTag is returned by renderers if the Tag of a Markup
+// is not a valid HTML tag name.
+var ErrTag = errors.New("dmp: invalid tag name in markup")
+
+// Markup describes an HTML element wrapping a part of a report.
+// If Tag is empty, the text is written without an element;
+// Class and Style are only added if they are not empty.
+type Markup struct {
+	Tag   string
+	Class string
+	Style string
+}
+
+func (m *Markup) open(w *errWriter) {
+	m.openID(w, "")
+}
+
+// Like open, adding an id attribute, if id is not empty.
+func (m *Markup) openID(w *errWriter, id string) {
+	if m.Tag == "" {
+		return
+	}
+	if !validTag(m.Tag) {
+		w.fail(ErrTag)
+		return
+	}
+	w.WriteString("<" + m.Tag)
+	if id != "" {
+		w.WriteString(` id="` + html.EscapeString(id) + `"`)
+	}
+	if m.Class != "" {
+		w.WriteString(` class="` + html.EscapeString(m.Class) + `"`)
+	}
+	if m.Style != "" {
+		w.WriteString(` style="` + html.EscapeString(m.Style) + `"`)
+	}
+	w.WriteString(">")
+}
+
+// Report whether tag consists of ASCII letters, digits, and
+// hyphens, starting with a letter.
+func validTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return tag != ""
+}
+
+func (m *Markup) close(w *errWriter) {
+	if m.Tag != "" {
+		w.WriteString("</" + m.Tag + ">")
+	}
+}
+
+func (m *Markup) wrap(w *errWriter, text string) {
+	m.open(w)
+	w.WriteString(text)
+	m.close(w)
+}
+
+// Like wrap, giving the element the id, and making
+// the text a link to the element with the id target.
+func (m *Markup) wrapLink(w *errWriter, text, id, target string) {
+	link := `<a href="#` + html.EscapeString(target) + `">`
+	if m.Tag == "" {
+		link = `<a id="` + html.EscapeString(id) + `" href="#` + html.EscapeString(target) + `">`
+	}
+	m.openID(w, id)
+	w.WriteString(link + text + "</a>")
+	m.close(w)
+}
+
+// HTMLRenderer renders a Diff list as inline HTML, wrapping each
+// diff into the element configured for its operation.
+type HTMLRenderer struct {
+	Insert Markup
+	Delete Markup
+	Equal  Markup
+
+	// Newline is written in place of each "\n" of the texts.
+	// If it is empty, line breaks are kept as they are.
+	Newline string
+
+	// Moves, as found by DetectMoves, are shown by wrapping their
+	// deletions into MoveDelete, and their insertions into MoveInsert.
+	// Both ends of a move link to each other, using the ids
+	// "move<k>-from" and "move<k>-to", where k is the index of the move.
+	Moves      []Move
+	MoveDelete Markup
+	MoveInsert Markup
+}
+
+// The renderer of PrettyHTML.
+var prettyHTML = HTMLRenderer{
+	Insert:  Markup{Tag: "ins", Style: "background:#e6ffe6;"},
+	Delete:  Markup{Tag: "del", Style: "background:#ffe6e6;"},
+	Equal:   Markup{Tag: "span"},
+	Newline: "&para;<br>",
+
+	MoveDelete: Markup{Tag: "del", Style: "background:#f0e6ff;"},
+	MoveInsert: Markup{Tag: "ins", Style: "background:#e6f0ff;"},
+}
+
+// Return a new HTMLRenderer producing the report of PrettyHTML,
+// which may be customized.
+func NewHTMLRenderer() *HTMLRenderer {
+	r := prettyHTML
+	return &r
+}
+
+func (r *HTMLRenderer) Render(w io.Writer, diffs Diffs) error {
+	ew := &errWriter{w: w}
+	moved := movedDiffs(r.Moves)
+	for i, d := range diffs {
+		text := html.EscapeString(d.Text)
+		if r.Newline != "" {
+			text = strings.Replace(text, "\n", r.Newline, -1)
+		}
+		if k, ok := moved[i]; ok {
+			from, to := "move"+strconv.Itoa(k)+"-from", "move"+strconv.Itoa(k)+"-to"
+			switch d.Op {
+			case Delete:
+				r.MoveDelete.wrapLink(ew, text, from, to)
+				continue
+			case Insert:
+				r.MoveInsert.wrapLink(ew, text, to, from)
+				continue
+			}
+		}
+		switch d.Op {
+		case Insert:
+			r.Insert.wrap(ew, text)
+		case Delete:
+			r.Delete.wrap(ew, text)
+		case Equal:
+			r.Equal.wrap(ew, text)
+		}
+	}
+	return ew.err
+}
+
+// An errWriter remembers the first error of a sequence of writes,
+// and skips any writes after that.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *errWriter) WriteString(s string) {
+	if w.err == nil {
+		_, w.err = io.WriteString(w.w, s)
+	}
+}
+
+func renderString(r Renderer, diffs Diffs) string {
+	var b strings.Builder
+	r.Render(&b, diffs)
+	return b.String()
+}
@@ -0,0 +1,170 @@
+// Diff Match and Patch – scoring of edit boundaries
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A Scorer rates the boundary between two texts, where an edit
+// starts or ends. CleanupSemanticLossless shifts each edit to the
+// position where the sum of the scores of its boundaries is highest,
+// preferring positions further right.
+type Scorer interface {
+	Score(one, two string) int
+}
+
+// The ScoreFunc type is an adapter allowing the use of functions
+// as Scorers.
+type ScoreFunc func(one, two string) int
+
+// Score calls f(one, two).
+func (f ScoreFunc) Score(one, two string) int {
+	return f(one, two)
+}
+
+var (
+	// DefaultScorer prefers, in this order, the edges of the texts,
+	// blank lines, line breaks, ends of sentences, white space,
+	// and other non-alphanumeric characters.
+	DefaultScorer Scorer = ScoreFunc(semanticScore)
+
+	// CodeScorer is meant for source code. It prefers line boundaries,
+	// and, like the indent heuristic of git, among these those followed
+	// by lines with less indentation, and those after blank lines; the
+	// boundaries before closing brackets are avoided.
+	CodeScorer Scorer = ScoreFunc(codeScore)
+
+	// CJKScorer is meant for Chinese, Japanese, and Korean text, which
+	// has no spaces between words. In addition to the boundaries
+	// preferred by DefaultScorer, it considers CJK punctuation, and
+	// changes of the script, like from Hiragana to Han.
+	CJKScorer Scorer = ScoreFunc(cjkScore)
+
+	// IdentifierScorer prefers the boundaries of the words of
+	// camelCase and snake_case identifiers, after those preferred
+	// by DefaultScorer.
+	IdentifierScorer Scorer = ScoreFunc(identifierScore)
+)
+
+func (c *Config) scorer() Scorer {
+	if c == nil || c.Scorer == nil {
+		return DefaultScorer
+	}
+	return c.Scorer
+}
+
+// Indentation exceeding this number of columns is not distinguished.
+const maxIndent = 200
+
+func codeScore(one, two string) int {
+	if one == "" || two == "" {
+		return 2*maxIndent + 16
+	}
+	if !strings.HasSuffix(one, "\n") {
+		// within a line
+		return semanticScore(one, two)
+	}
+	score := 8
+
+	// Find the next non-blank line.
+	line, blank := "", false
+	for rest := two; rest != ""; {
+		i := strings.IndexByte(rest, '\n') + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		line, rest = rest[:i], rest[i:]
+		if strings.TrimSpace(line) != "" {
+			break
+		}
+		line, blank = "", true
+	}
+	indent, rest := indentation(line)
+	score += maxIndent - indent
+	if strings.HasPrefix(rest, "}") || strings.HasPrefix(rest, ")") || strings.HasPrefix(rest, "]") {
+		score -= maxIndent
+	}
+	if blank {
+		score += 2
+	}
+	if prev := lastLine(one[:len(one)-1]); strings.TrimSpace(prev) == "" {
+		score += 4
+	}
+	return score
+}
+
+// Return the number of columns line is indented by, with tab stops
+// every eight columns, and the rest of the line.
+func indentation(line string) (columns int, rest string) {
+	for i, r := range line {
+		switch r {
+		case ' ':
+			columns++
+		case '\t':
+			columns += 8 - columns%8
+		default:
+			return min(columns, maxIndent), line[i:]
+		}
+	}
+	return min(columns, maxIndent), ""
+}
+
+func lastLine(s string) string {
+	return s[strings.LastIndexByte(s, '\n')+1:]
+}
+
+func cjkScore(one, two string) int {
+	score := semanticScore(one, two)
+	if one == "" || two == "" {
+		return score
+	}
+	r1, r2 := lastRune(one), firstRune(two)
+	cjk := 0
+	switch {
+	case strings.ContainsRune("。！？．", r1):
+		// end of sentences
+		cjk = 3
+	case strings.ContainsRune("、，；：」』）】〕", r1), strings.ContainsRune("「『（【〔", r2):
+		cjk = 2
+	case unicode.Is(unicode.Hiragana, r1) && !unicode.Is(unicode.Hiragana, r2):
+		// after particles, and inflections
+		cjk = 2
+	case cjkScript(r1) != cjkScript(r2):
+		cjk = 1
+	}
+	return max(score, cjk)
+}
+
+// Return the CJK script of r, or nil.
+func cjkScript(r rune) *unicode.RangeTable {
+	for _, t := range []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul} {
+		if unicode.Is(t, r) {
+			return t
+		}
+	}
+	return nil
+}
+
+func identifierScore(one, two string) int {
+	if score := semanticScore(one, two); score != 0 {
+		return score + 1
+	}
+	r1, r2 := lastRune(one), firstRune(two)
+	switch {
+	case unicode.IsUpper(r2) && !unicode.IsUpper(r1):
+		// camelCase
+		return 2
+	case unicode.IsUpper(r1) && unicode.IsUpper(r2) && unicode.IsLower(firstRune(two[len(firstUTF8(two)):])):
+		// the end of an acronym, like in HTTPServer
+		return 2
+	case unicode.IsDigit(r1) != unicode.IsDigit(r2):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,239 @@
+// Diff Match and Patch – side-by-side HTML report
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A lineBlock is a run of whole lines of text1 and text2, either
+// unchanged, or touched by at least one edit. In the latter case
+// diffs contains the character-level differences of the block.
+type lineBlock struct {
+	equal bool
+	diffs Diffs
+}
+
+// Group a diff into blocks of whole lines. Lines that are part of
+// an equality on both sides form equal blocks, all other lines
+// are collected into changed blocks.
+func (diffs Diffs) lineBlocks() (blocks []lineBlock) {
+	var pending Diffs
+	atStart1, atStart2 := true, true
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		equal := true
+		for _, d := range pending {
+			if d.Op != Equal {
+				equal = false
+			}
+		}
+		blocks = appendLineBlock(blocks, lineBlock{equal, pending})
+		pending = nil
+	}
+
+	for _, d := range diffs {
+		text := d.Text
+		if text == "" {
+			continue
+		}
+		switch d.Op {
+		case Delete:
+			pending.add(Delete, text)
+			atStart1 = strings.HasSuffix(text, "\n")
+			continue
+		case Insert:
+			pending.add(Insert, text)
+			atStart2 = strings.HasSuffix(text, "\n")
+			continue
+		}
+
+		if !atStart1 || !atStart2 {
+			// Complete the current line, which has been changed.
+			i := strings.IndexByte(text, '\n')
+			if i == -1 {
+				pending.add(Equal, text)
+				atStart1, atStart2 = false, false
+				continue
+			}
+			pending.add(Equal, text[:i+1])
+			text = text[i+1:]
+			atStart1, atStart2 = true, true
+		}
+		if text == "" {
+			continue
+		}
+		flush()
+		if i := strings.LastIndexByte(text, '\n'); i != -1 {
+			blocks = appendLineBlock(blocks, lineBlock{true, Diffs{{Equal, text[:i+1]}}})
+			text = text[i+1:]
+		}
+		if text != "" {
+			pending.add(Equal, text)
+			atStart1, atStart2 = false, false
+		}
+	}
+	flush()
+	return
+}
+
+func appendLineBlock(blocks []lineBlock, b lineBlock) []lineBlock {
+	if n := len(blocks); n != 0 && b.equal && blocks[n-1].equal {
+		blocks[n-1].diffs[0].Text += b.diffs.Text1()
+		return blocks
+	}
+	if b.equal {
+		b.diffs = Diffs{{Equal, b.diffs.Text1()}}
+	}
+	return append(blocks, b)
+}
+
+// A segment is a part of a line, tagged with the operation,
+// and the index, of the diff it originates from.
+type segment struct {
+	op    int
+	text  string
+	index int
+}
+
+// Split one side of a diff into lines of segments. If op is
+// Delete, the lines of text1 are returned, if it is Insert,
+// the lines of text2.
+func (diffs Diffs) sideLines(op int) (lines [][]segment) {
+	var line []segment
+	for i, d := range diffs {
+		if d.Op != Equal && d.Op != op {
+			continue
+		}
+		text := d.Text
+		for text != "" {
+			n := strings.IndexByte(text, '\n')
+			if n == -1 {
+				line = append(line, segment{d.Op, text, i})
+				break
+			}
+			line = append(line, segment{d.Op, text[:n+1], i})
+			lines = append(lines, line)
+			line = nil
+			text = text[n+1:]
+		}
+	}
+	if line != nil {
+		lines = append(lines, line)
+	}
+	return
+}
+
+// SideBySideRenderer renders a Diff list as a two-column HTML table.
+// Lines of text1 are shown on the left, lines of text2 on the right,
+// each preceded by its line number. Unchanged lines are aligned; within
+// changed lines, the deleted and inserted parts are wrapped into the
+// Delete and Insert elements. The remaining fields are the class names
+// of the table and its cells, so that styling can be left to CSS.
+type SideBySideRenderer struct {
+	Table   string
+	LineNum string
+	Equal   string
+	Changed [2]string // class of changed lines of text1, and of text2
+	Empty   string    // class of cells filling up a shorter side
+
+	Delete Markup
+	Insert Markup
+}
+
+// The renderer of SideBySideHTML.
+var sideBySideHTML = SideBySideRenderer{
+	Table:   "diff",
+	LineNum: "lineno",
+	Equal:   "equal",
+	Changed: [2]string{"delete", "insert"},
+	Empty:   "empty",
+	Delete:  Markup{Tag: "del"},
+	Insert:  Markup{Tag: "ins"},
+}
+
+// Return a new SideBySideRenderer producing the report of
+// SideBySideHTML, which may be customized.
+func NewSideBySideRenderer() *SideBySideRenderer {
+	r := sideBySideHTML
+	return &r
+}
+
+// Convert a Diff list into a two-column HTML table, using
+// the markup of NewSideBySideRenderer.
+func (diffs Diffs) SideBySideHTML() string {
+	return renderString(&sideBySideHTML, diffs)
+}
+
+func (r *SideBySideRenderer) Render(w io.Writer, diffs Diffs) error {
+	ew := &errWriter{w: w}
+	n1, n2 := 0, 0
+
+	ew.WriteString(`<table class="` + html.EscapeString(r.Table) + `">` + "\n")
+	for _, blk := range diffs.lineBlocks() {
+		if blk.equal {
+			for _, line := range blk.diffs.sideLines(Equal) {
+				n1++
+				n2++
+				ew.WriteString("<tr>")
+				r.writeCell(ew, n1, r.Equal, line)
+				r.writeCell(ew, n2, r.Equal, line)
+				ew.WriteString("</tr>\n")
+			}
+			continue
+		}
+		left := blk.diffs.sideLines(Delete)
+		right := blk.diffs.sideLines(Insert)
+		for i := 0; i < len(left) || i < len(right); i++ {
+			ew.WriteString("<tr>")
+			if i < len(left) {
+				n1++
+				r.writeCell(ew, n1, r.Changed[0], left[i])
+			} else {
+				r.writeCell(ew, 0, r.Empty, nil)
+			}
+			if i < len(right) {
+				n2++
+				r.writeCell(ew, n2, r.Changed[1], right[i])
+			} else {
+				r.writeCell(ew, 0, r.Empty, nil)
+			}
+			ew.WriteString("</tr>\n")
+		}
+	}
+	ew.WriteString("</table>\n")
+	return ew.err
+}
+
+func (r *SideBySideRenderer) writeCell(w *errWriter, lineNum int, class string, line []segment) {
+	w.WriteString(`<td class="` + html.EscapeString(r.LineNum) + `">`)
+	if lineNum != 0 {
+		w.WriteString(strconv.Itoa(lineNum))
+	}
+	w.WriteString(`</td><td class="` + html.EscapeString(class) + `">`)
+	for _, s := range line {
+		text := html.EscapeString(strings.TrimSuffix(s.text, "\n"))
+		if text == "" {
+			continue
+		}
+		switch s.op {
+		case Insert:
+			r.Insert.wrap(w, text)
+		case Delete:
+			r.Delete.wrap(w, text)
+		default:
+			w.WriteString(text)
+		}
+	}
+	w.WriteString("</td>")
+}
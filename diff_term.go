@@ -0,0 +1,189 @@
+// Diff Match and Patch – terminal renderer
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape sequences used by the TermRenderer.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiBold    = "\x1b[1m"
+	ansiNormal  = "\x1b[22m"
+)
+
+// TermRenderer renders a Diff list line by line for display on a
+// terminal. Unchanged lines are prefixed by a space, lines of text1
+// touched by an edit by "-", and lines of text2 touched by an edit
+// by "+". It works with both character-mode and line-mode results.
+type TermRenderer struct {
+	// If Color is set, removed lines are coloured red, and added
+	// lines green, using ANSI escape sequences. Where a line has
+	// been changed only partly, the deleted or inserted parts are
+	// highlighted in bold. Otherwise plain text is written.
+	Color bool
+
+	// If ShowSpace is set, whitespace within deleted and inserted
+	// parts is made visible: spaces are shown as "·", tabs as "→",
+	// and changed line ends as "↵".
+	ShowSpace bool
+
+	// If Unified is set, only changed lines are shown, together with
+	// Context lines of unchanged text around them. They are grouped
+	// into hunks, each preceded by a "@@ -l,s +l,s @@" header.
+	Unified bool
+	Context int
+
+	// Moves, as found by DetectMoves, are shown like with git's
+	// --color-moved, if Color is set: Lines consisting of moved text
+	// only are coloured magenta, if removed, and blue, if added.
+	Moves []Move
+}
+
+// The state of rendering a Diff list.
+type termState struct {
+	moved map[int]bool // ordinals of the moved edits
+	edits int          // number of edits written so far
+}
+
+var visibleSpace = strings.NewReplacer(" ", "·", "\t", "→", "\n", "↵")
+
+func (r *TermRenderer) Render(w io.Writer, diffs Diffs) error {
+	ew := &errWriter{w: w}
+	st := &termState{moved: make(map[int]bool)}
+	if len(r.Moves) != 0 {
+		// Edits are identified by their ordinals, as the
+		// diffs written may differ from the ones of the list.
+		moved := movedDiffs(r.Moves)
+		n := 0
+		for i, d := range diffs {
+			if d.Op == Equal || d.Text == "" {
+				continue
+			}
+			if _, ok := moved[i]; ok {
+				st.moved[n] = true
+			}
+			n++
+		}
+	}
+	if !r.Unified {
+		r.writeLines(ew, st, diffs)
+		return ew.err
+	}
+	for _, h := range diffs.Hunks(r.Context, Lines) {
+		header := "@@ -" + unifiedRange(h.Start1, h.Len1) + " +" + unifiedRange(h.Start2, h.Len2) + " @@"
+		if r.Color {
+			header = ansiCyan + header + ansiReset
+		}
+		ew.WriteString(header + "\n")
+		r.writeLines(ew, st, h.Diffs)
+	}
+	return ew.err
+}
+
+// Format the line range of a hunk the way diff -u does.
+func unifiedRange(start, n int) string {
+	if n == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if n == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(n)
+}
+
+func (r *TermRenderer) writeLines(ew *errWriter, st *termState, diffs Diffs) {
+	for _, blk := range diffs.lineBlocks() {
+		if blk.equal {
+			for _, line := range blk.diffs.sideLines(Equal) {
+				r.writeLine(ew, ' ', line, false)
+			}
+			continue
+		}
+
+		// Find the moved edits of the block.
+		moved := make(map[int]bool)
+		for i, d := range blk.diffs {
+			if d.Op != Equal {
+				moved[i] = st.moved[st.edits]
+				st.edits++
+			}
+		}
+		for _, line := range blk.diffs.sideLines(Delete) {
+			r.writeLine(ew, '-', line, movedLine(line, moved))
+		}
+		for _, line := range blk.diffs.sideLines(Insert) {
+			r.writeLine(ew, '+', line, movedLine(line, moved))
+		}
+	}
+}
+
+// Report whether line contains edits, which are all moved.
+func movedLine(line []segment, moved map[int]bool) bool {
+	found := false
+	for _, s := range line {
+		if s.op == Equal {
+			continue
+		}
+		if !moved[s.index] {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func (r *TermRenderer) writeLine(w *errWriter, prefix byte, line []segment, moved bool) {
+	color := ""
+	if r.Color {
+		switch {
+		case prefix == '-' && moved:
+			color = ansiMagenta
+		case prefix == '+' && moved:
+			color = ansiBlue
+		case prefix == '-':
+			color = ansiRed
+		case prefix == '+':
+			color = ansiGreen
+		}
+	}
+
+	// Only highlight the edits of a line if part of it is unchanged.
+	partly := false
+	for _, s := range line {
+		if s.op == Equal {
+			partly = true
+		}
+	}
+
+	w.WriteString(color + string(prefix))
+	for _, s := range line {
+		text := s.text
+		if s.op == Equal || !r.ShowSpace {
+			text = strings.TrimSuffix(text, "\n")
+		} else {
+			text = visibleSpace.Replace(text)
+		}
+		if color != "" && partly && s.op != Equal {
+			w.WriteString(ansiBold + text + ansiNormal)
+		} else {
+			w.WriteString(text)
+		}
+	}
+	if color != "" {
+		w.WriteString(ansiReset)
+	}
+	w.WriteString("\n")
+}
@@ -8,11 +8,15 @@
 package dmp
 
 import (
+	"fmt"
 	. "github.com/knieriem/dmp/rstring"
+	"math/rand"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
+	"unicode"
 )
 
 func TestCommonPrefix(t *testing.T) {
@@ -172,6 +176,8 @@ func TestDiffCleanupMerge(t *testing.T) {
 			"=<x> -<a> +<abc> -<dc> =<y>",
 			"=<xa> -<d> +<b> =<cy>",
 		},
+		{"Prefix detection at start", "-<abc> +<abd> =<x> -<q>", "=<ab> -<c> +<d> =<x> -<q>"},
+		{"Prefix detection at start, no equality", "-<ab> +<ac>", "=<a> -<b> +<c>"},
 		{"Slide edit left", "=<a> +<ba> =<c>", "+<ab> =<ac>"},
 		{"Slide edit right", "=<c> +<ab> =<a>", "=<ca> +<ba>"},
 		{"Slide edit left recursive", "=<a> -<b> =<c> -<ac> =<x>", "-<abc> =<acx>"},
@@ -207,6 +213,8 @@ func TestDiffCleanupSemanticLossless(t *testing.T) {
 		},
 		{"Hitting the start", "=<a> -<a> =<ax>", "-<a> =<aax>"},
 		{"Hitting the end", "=<xa> -<a> =<a>", "=<xaa> -<a>"},
+		{"Multibyte shift right", "=<ä> +<äb> =<äc>", "=<ää> +<bä> =<c>"},
+		{"Multibyte word boundaries", "=<The ä> +<äbc ä> =<äd.>", "=<The > +<ääbc > =<ääd.>"},
 		{
 			"Sentence boundaries",
 			"=<The xxx. The > +<zzz. The > =<yyy.>",
@@ -314,11 +322,195 @@ func TestDiffCleanupEfficiency(t *testing.T) {
 	}
 }
 
+func TestDiffMapIndex(t *testing.T) {
+	diffs := diffList("=<The > +<big > =<cat>")
+	assertEquals("Insertion, right bias", 8, diffs.MapIndex(4, Bytes, BiasRight), t)
+	assertEquals("Insertion, left bias", 4, diffs.MapIndex(4, Bytes, BiasLeft), t)
+	assertEquals("Reverse, within insertion", 4, diffs.MapIndexReverse(5, Bytes, BiasRight), t)
+	assertEquals("Reverse, after insertion", 5, diffs.MapIndexReverse(9, Bytes, BiasLeft), t)
+
+	diffs = diffList("=<ab> -<cde> +<XY> =<f>")
+	for _, x := range []struct{ loc, left, right int }{
+		{1, 1, 1},
+		{2, 2, 2},
+		{3, 2, 4},
+		{5, 4, 4},
+		{6, 5, 5},
+	} {
+		assertEquals(fmt.Sprint("Replacement, left bias, ", x.loc), x.left, diffs.MapIndex(x.loc, Bytes, BiasLeft), t)
+		assertEquals(fmt.Sprint("Replacement, right bias, ", x.loc), x.right, diffs.MapIndex(x.loc, Bytes, BiasRight), t)
+	}
+	start, end := diffs.MapRange(2, 5, Bytes, BiasRight, BiasLeft)
+	assertEquals("Replaced range", "2 4", fmt.Sprint(start, end), t)
+	start, end = diffs.MapRange(3, 4, Bytes, BiasRight, BiasLeft)
+	assertEquals("Range within replacement", "4 4", fmt.Sprint(start, end), t)
+	start, end = diffs.MapRangeReverse(1, 4, Bytes, BiasLeft, BiasRight)
+	assertEquals("Reverse range", "1 5", fmt.Sprint(start, end), t)
+
+	diffs = Diffs{{Equal, "ä😀"}, {Insert, "x"}, {Equal, "b"}}
+	assertEquals("Bytes", 7, diffs.MapIndex(6, Bytes, BiasRight), t)
+	assertEquals("Runes", 3, diffs.MapIndex(2, Runes, BiasRight), t)
+	assertEquals("UTF-16", 4, diffs.MapIndex(3, UTF16, BiasRight), t)
+	assertEquals("UTF-16 reverse", 3, diffs.MapIndexReverse(4, UTF16, BiasRight), t)
+}
+
+func TestDiffMapper(t *testing.T) {
+	diffs := diffList("+<x> =<ab> -<cde> +<XY> =<f> +<gh> -<i> =<jk> +<l>")
+	for _, reverse := range []bool{false, true} {
+		m := NewMapper(diffs, Runes)
+		text := diffs.Text1()
+		if reverse {
+			m = NewReverseMapper(diffs, Runes)
+			text = diffs.Text2()
+		}
+		var locs []int
+		for loc := 0; loc <= len(text)+1; loc++ {
+			locs = append(locs, loc)
+		}
+		for _, bias := range []Bias{BiasLeft, BiasRight} {
+			sorted := m.MapSorted(locs, bias)
+			for _, loc := range locs {
+				want, deleted := diffs.mapIndex(loc, Runes, bias, reverse)
+				name := fmt.Sprint("reverse=", reverse, " bias=", bias, " loc=", loc)
+				assertEquals(name, fmt.Sprint(Mapping{want, deleted}), fmt.Sprint(m.Map(loc, bias)), t)
+				assertEquals(name+" sorted", fmt.Sprint(Mapping{want, deleted}), fmt.Sprint(sorted[loc]), t)
+			}
+		}
+	}
+
+	m := NewMapper(diffs, Runes)
+	assertEquals("Deleted", "[{0 false} {2 false} {3 true} {3 true} {5 false} {6 true}]",
+		fmt.Sprint(m.MapSorted([]int{0, 1, 2, 3, 5, 6}, BiasLeft)), t)
+	assertEquals("Unsorted", "[{5 false} {0 false} {3 true}]", fmt.Sprint(m.MapSorted([]int{5, 0, 2}, BiasLeft)), t)
+}
+
 func TestDiffPrettyHTML(t *testing.T) {
 	diffs := Diffs{{Equal, "a\n"}, {Delete, "<B>b</B>"}, {Insert, "c&d"}}
 	assertEquals("-", "<span>a&para;<br></span><del style=\"background:#ffe6e6;\">&lt;B&gt;b&lt;/B&gt;</del><ins style=\"background:#e6ffe6;\">c&amp;d</ins>", diffs.PrettyHTML(), t)
 }
 
+func TestDiffHTMLRenderer(t *testing.T) {
+	r := &HTMLRenderer{
+		Insert:  Markup{Tag: "span", Class: "added"},
+		Delete:  Markup{Tag: "span", Class: "removed"},
+		Newline: "<br>",
+	}
+	var b strings.Builder
+	diffs := Diffs{{Equal, "a\n"}, {Delete, "<B>b</B>"}, {Insert, "c&d"}}
+	assertTrue("No error", r.Render(&b, diffs) == nil, t)
+	assertEquals("Custom markup", `a<br><span class="removed">&lt;B&gt;b&lt;/B&gt;</span><span class="added">c&amp;d</span>`, b.String(), t)
+
+	for _, tag := range []string{"span x", "a>", "1h", "x\"", "<b"} {
+		r.Insert.Tag = tag
+		assertTrue("Invalid tag "+tag, r.Render(&b, diffs) == ErrTag, t)
+	}
+
+	r = NewHTMLRenderer()
+	r.Delete.Tag = "s"
+	assertEquals("Default markup", `<span>a&para;<br></span><s style="background:#ffe6e6;">x</s>`, renderString(r, diffList("=<a\n> -<x>")), t)
+	assertEquals("PrettyHTML unaffected", `<del style="background:#ffe6e6;">x</del>`, diffList("-<x>").PrettyHTML(), t)
+}
+
+func TestDiffSideBySideHTML(t *testing.T) {
+	diffs := Diffs{{Equal, "a\nb"}, {Delete, "x"}, {Insert, "<y"}, {Equal, "\nc\n"}, {Insert, "d\n"}, {Equal, "e"}}
+	assertEquals("Changed and inserted lines", `<table class="diff">
+<tr><td class="lineno">1</td><td class="equal">a</td><td class="lineno">1</td><td class="equal">a</td></tr>
+<tr><td class="lineno">2</td><td class="delete">b<del>x</del></td><td class="lineno">2</td><td class="insert">b<ins>&lt;y</ins></td></tr>
+<tr><td class="lineno">3</td><td class="equal">c</td><td class="lineno">3</td><td class="equal">c</td></tr>
+<tr><td class="lineno"></td><td class="empty"></td><td class="lineno">4</td><td class="insert"><ins>d</ins></td></tr>
+<tr><td class="lineno">4</td><td class="equal">e</td><td class="lineno">5</td><td class="equal">e</td></tr>
+</table>
+`, diffs.SideBySideHTML(), t)
+
+	r := NewSideBySideRenderer()
+	r.Table = "custom"
+	assertTrue("Custom table", strings.HasPrefix(renderString(r, diffs), `<table class="custom">`), t)
+	assertTrue("SideBySideHTML unaffected", strings.HasPrefix(diffs.SideBySideHTML(), `<table class="diff">`), t)
+}
+
+func TestDiffTermRenderer(t *testing.T) {
+	f := func(r *TermRenderer, diffs Diffs) string {
+		var b strings.Builder
+		r.Render(&b, diffs)
+		return b.String()
+	}
+	diffs := diffList("=<a\nb> -<x> +<y z> =<\nc\n> -<d\n>")
+	assertEquals("Plain", " a\n-bx\n+by z\n c\n-d\n", f(&TermRenderer{}, diffs), t)
+	assertEquals("Plain, whitespace shown", " a\n-bx\n+by·z\n c\n-d↵\n", f(&TermRenderer{ShowSpace: true}, diffs), t)
+	assertEquals("Color",
+		" a\n"+
+			"\x1b[31m-b\x1b[1mx\x1b[22m\x1b[0m\n"+
+			"\x1b[32m+b\x1b[1my z\x1b[22m\x1b[0m\n"+
+			" c\n"+
+			"\x1b[31m-d\x1b[0m\n",
+		f(&TermRenderer{Color: true}, diffs), t)
+
+	// Line-mode results consist of whole lines.
+	diffs = diffList("=<a\n> -<b\n> +<c\n>")
+	assertEquals("Line mode", " a\n-b\n+c\n", f(&TermRenderer{}, diffs), t)
+}
+
+func TestDiffHunks(t *testing.T) {
+	text1 := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	text2 := "1\n2\nthree\n4\n5\n6\n7\n8\nnine\n"
+	diffs := DiffMain(text1, text2, false, 0)
+
+	hunks := diffs.Hunks(1, Lines)
+	assertEquals("Two hunks", 2, len(hunks), t)
+	h := hunks[0]
+	assertEquals("Hunk 1 position", "1 3 1 3", fmt.Sprint(h.Start1, h.Len1, h.Start2, h.Len2), t)
+	assertEquals("Hunk 1 diffs", diffList("=<2\n> -<3> +<three> =<\n4\n>"), h.Diffs, t)
+	h = hunks[1]
+	assertEquals("Hunk 2 position", "7 2 7 2", fmt.Sprint(h.Start1, h.Len1, h.Start2, h.Len2), t)
+	assertEquals("Hunk 2 diffs", diffList("=<8\n> -<9> +<nine> =<\n>"), h.Diffs, t)
+
+	hunks = diffs.Hunks(3, Lines)
+	assertEquals("Merged hunks", 1, len(hunks), t)
+	assertEquals("Merged hunk position", "0 9 0 9", fmt.Sprint(hunks[0].Start1, hunks[0].Len1, hunks[0].Start2, hunks[0].Len2), t)
+
+	diffs = diffList("=<abcäöü> -<x> +<yz> =<123456>")
+	hunks = diffs.Hunks(2, Runes)
+	assertEquals("Runes", 1, len(hunks), t)
+	assertEquals("Runes diffs", diffList("=<öü> -<x> +<yz> =<12>"), hunks[0].Diffs, t)
+	assertEquals("Runes position", "4 5 4 6", fmt.Sprint(hunks[0].Start1, hunks[0].Len1, hunks[0].Start2, hunks[0].Len2), t)
+	hunks = diffs.Hunks(3, Bytes)
+	assertEquals("Bytes diffs", diffList("=<öü> -<x> +<yz> =<123>"), hunks[0].Diffs, t)
+	assertEquals("Bytes position", "5 8 5 9", fmt.Sprint(hunks[0].Start1, hunks[0].Len1, hunks[0].Start2, hunks[0].Len2), t)
+
+	// No context.
+	hunks = diffs.Hunks(0, Bytes)
+	assertEquals("Bytes, no context", diffList("-<x> +<yz>"), hunks[0].Diffs, t)
+	assertEquals("Bytes, no context position", "9 1 9 2", fmt.Sprint(hunks[0].Start1, hunks[0].Len1, hunks[0].Start2, hunks[0].Len2), t)
+	hunks = diffs.Hunks(0, Runes)
+	assertEquals("Runes, no context", diffList("-<x> +<yz>"), hunks[0].Diffs, t)
+	hunks = Diffs{{Equal, "ab"}, {Insert, "x"}}.Hunks(0, Bytes)
+	assertEquals("Leading equality, no context", diffList("+<x>"), hunks[0].Diffs, t)
+
+	assertEquals("No changes", 0, len(diffList("=<abc>").Hunks(3, Lines)), t)
+	assertEquals("Whole diff", 1, len(DiffMain(text1, text2, false, 0).Hunks(-1, Lines)), t)
+
+	var b strings.Builder
+	r := &TermRenderer{Unified: true, Context: 1}
+	r.Render(&b, DiffMain(text1, text2, false, 0))
+	assertEquals("Unified", "@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n@@ -8,2 +8,2 @@\n 8\n-9\n+nine\n", b.String(), t)
+}
+
+func TestDiffEdits(t *testing.T) {
+	diffs := Diffs{{Equal, "a😀\n"}, {Delete, "bä"}, {Insert, "c"}, {Equal, "\nd"}}
+	for _, x := range []struct {
+		unit  Unit
+		edits []Edit
+	}{
+		{Bytes, []Edit{{Equal, 0, 6, 0, 6}, {Delete, 6, 9, 6, 6}, {Insert, 9, 9, 6, 7}, {Equal, 9, 11, 7, 9}}},
+		{Runes, []Edit{{Equal, 0, 3, 0, 3}, {Delete, 3, 5, 3, 3}, {Insert, 5, 5, 3, 4}, {Equal, 5, 7, 4, 6}}},
+		{UTF16, []Edit{{Equal, 0, 4, 0, 4}, {Delete, 4, 6, 4, 4}, {Insert, 6, 6, 4, 5}, {Equal, 6, 8, 5, 7}}},
+		{Lines, []Edit{{Equal, 0, 1, 0, 1}, {Delete, 1, 1, 1, 1}, {Insert, 1, 1, 1, 1}, {Equal, 1, 2, 1, 2}}},
+	} {
+		edits := diffs.Edits(x.unit)
+		assertEquals(fmt.Sprint("Unit ", x.unit), fmt.Sprint(x.edits), fmt.Sprint(edits), t)
+	}
+}
+
 func TestDiffText(t *testing.T) {
 	// Compute the source and destination texts.
 	diffs := diffList("=<jump> -<s> +<ed> =< over > -<the> +<a> =< lazy>")
@@ -326,6 +518,589 @@ func TestDiffText(t *testing.T) {
 	assertEquals("diff_text2", "jumped over a lazy", diffs.Text2(), t)
 }
 
+func TestDiffApply(t *testing.T) {
+	diffs := diffList("=<jump> -<s> +<ed> =< over > -<the> +<a> =< lazy>")
+	text2, err := diffs.Apply("jumps over the lazy")
+	assertTrue("No error", err == nil, t)
+	assertEquals("Applied", "jumped over a lazy", text2, t)
+
+	for _, x := range []struct {
+		text1        string
+		offset, diff int
+	}{
+		{"jumps over thy lazy", 13, 4},
+		{"jumpx over the lazy", 4, 1},
+		{"jumps over the lazy dog", 19, -1},
+		{"jumps over the", 14, 6},
+	} {
+		_, err = diffs.Apply(x.text1)
+		e, ok := err.(*MismatchError)
+		assertTrue(x.text1+": MismatchError", ok, t)
+		if ok {
+			assertEquals(x.text1+": offset", x.offset, e.Offset, t)
+			assertEquals(x.text1+": index", x.diff, e.Index, t)
+		}
+	}
+}
+
+func TestDiffDelta(t *testing.T) {
+	// Convert a diff into delta string.
+	diffs := diffList("=<jump> -<s> +<ed> =< over > -<the> +<a> =< lazy> +<old dog>")
+	text1 := diffs.Text1()
+	assertEquals("diff_text1", "jumps over the lazy", text1, t)
+
+	delta := diffs.ToDelta()
+	assertEquals("diff_toDelta", "=4\t-1\t+ed\t=6\t-3\t+a\t=5\t+old dog", delta, t)
+
+	// Convert delta string into a diff.
+	d, err := FromDelta(text1, delta)
+	assertTrue("diff_fromDelta: No error", err == nil, t)
+	assertEquals("diff_fromDelta: Normal", diffs, d, t)
+
+	// Generates error (19 != 20).
+	_, err = FromDelta(text1+"x", delta)
+	assertTrue("diff_fromDelta: Too long", err == ErrDeltaLength, t)
+
+	// Generates error (19 != 18).
+	_, err = FromDelta(text1[1:], delta)
+	assertTrue("diff_fromDelta: Too short", err == ErrDeltaLength, t)
+
+	// Generates error (%c3%xy invalid Unicode).
+	_, err = FromDelta("", "+%c3%xy")
+	assertTrue("diff_fromDelta: Invalid character", err != nil, t)
+
+	_, err = FromDelta("abc", "=1\t*2")
+	assertTrue("diff_fromDelta: Invalid operation", err == ErrDeltaOperation, t)
+
+	// Test deltas with special characters.
+	diffs = Diffs{{Equal, "\u0680 \x00 \t %"}, {Delete, "\u0681 \x01 \n ^"}, {Insert, "\u0682 \x02 \\ |"}}
+	text1 = diffs.Text1()
+	assertEquals("diff_text1: Unicode text", "\u0680 \x00 \t %\u0681 \x01 \n ^", text1, t)
+
+	delta = diffs.ToDelta()
+	assertEquals("diff_toDelta: Unicode", "=7\t-7\t+%DA%82 %02 %5C %7C", delta, t)
+
+	d, err = FromDelta(text1, delta)
+	assertTrue("diff_fromDelta: Unicode: No error", err == nil, t)
+	assertEquals("diff_fromDelta: Unicode", diffs, d, t)
+
+	// Lengths are counted in UTF-16 code units.
+	diffs = Diffs{{Equal, "a\U0001F600"}, {Delete, "b"}, {Insert, "\U0001F601"}}
+	delta = diffs.ToDelta()
+	assertEquals("diff_toDelta: Surrogate pairs", "=3\t-1\t+%F0%9F%98%81", delta, t)
+	d, err = FromDelta(diffs.Text1(), delta)
+	assertTrue("diff_fromDelta: Surrogate pairs: No error", err == nil, t)
+	assertEquals("diff_fromDelta: Surrogate pairs", diffs, d, t)
+
+	_, err = FromDelta(diffs.Text1(), "=2\t-2")
+	assertTrue("diff_fromDelta: Split surrogate pair", err == ErrDeltaLength, t)
+
+	// Verify pool of unchanged characters.
+	diffs = Diffs{{Insert, "A-Z a-z 0-9 - _ . ! ~ * ' ( ) ; / ? : @ & = + $ , # "}}
+	text2 := diffs.Text2()
+	assertEquals("diff_text2: Unchanged characters", "A-Z a-z 0-9 - _ . ! ~ * ' ( ) ; / ? : @ & = + $ , # ", text2, t)
+
+	delta = diffs.ToDelta()
+	assertEquals("diff_toDelta: Unchanged characters", "+A-Z a-z 0-9 - _ . ! ~ * ' ( ) ; / ? : @ & = + $ , # ", delta, t)
+
+	// Convert delta string into a diff.
+	d, err = FromDelta("", delta)
+	assertTrue("diff_fromDelta: Unchanged characters: No error", err == nil, t)
+	assertEquals("diff_fromDelta: Unchanged characters", diffs, d, t)
+}
+
+func TestDiffTokens(t *testing.T) {
+	tdiffs := DiffTokens(strings.Fields("the quick brown fox"), strings.Fields("the slow brown fox jumps"), 0)
+	assertEquals("Tokens", "[{61 [the]} {45 [quick]} {43 [slow]} {61 [brown fox]} {43 [jumps]}]", fmt.Sprint(tdiffs), t)
+	assertEquals("Diffs", diffList("=<the> -<quick> +<slow> =<brownfox> +<jumps>"), tdiffs.Diffs(), t)
+
+	tdiffs = DiffTokens([]string{"a", "", "b"}, []string{"a", "c", "b"}, 0)
+	assertEquals("Empty token", diffList("=<a> +<c> =<b>"), tdiffs.Diffs(), t)
+
+	// More tokens than characters below the surrogate range.
+	n := 0xE000
+	tokens1 := make([]string, n)
+	for i := range tokens1 {
+		tokens1[i] = fmt.Sprint(i, " ")
+	}
+	tokens2 := append([]string(nil), tokens1...)
+	tokens2[n-2] = "x "
+	tdiffs = DiffTokens(tokens1, tokens2, 0)
+	assertEquals("Many tokens", fmt.Sprint([]string{fmt.Sprint(n-2, " ")}, []string{"x "}), fmt.Sprint(tdiffs[1].Tokens, tdiffs[2].Tokens), t)
+	assertEquals("Many tokens: count", 4, len(tdiffs), t)
+}
+
+func TestGraphemeBoundary(t *testing.T) {
+	for _, x := range []struct {
+		name     string
+		s        string
+		clusters int
+	}{
+		{"ASCII", "abc", 3},
+		{"Combining accent", "e\u0301a", 2},
+		{"CR LF", "a\r\nb", 3},
+		{"Hangul jamo", "\u1100\u1161\u11A8\uAC00", 2},
+		{"ZWJ sequence", "\U0001F468\u200D\U0001F469\u200D\U0001F467x", 2},
+		{"Skin tone", "\U0001F44D\U0001F3FD", 1},
+		{"Flags", "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7\U0001F1EE", 3},
+		{"ZWJ after letter", "a\u200D\U0001F469", 2},
+	} {
+		assertEquals(x.name, x.clusters, len(graphemes(x.s)), t)
+	}
+}
+
+func TestConfigGraphemes(t *testing.T) {
+	c := &Config{Graphemes: true}
+	for _, x := range []struct{ name, text1, text2, diffs string }{
+		{"Accent changed", "caf\u00E9 e\u0301t\u00E9", "caf\u00E9 e\u0300t\u00E9",
+			"=<caf\u00E9 > -<e\u0301> +<e\u0300> =<t\u00E9>"},
+		{"Accent added", "cafe", "cafe\u0301",
+			"=<caf> -<e> +<e\u0301>"},
+		{"Emoji sequence", "\U0001F468\u200D\U0001F469", "\U0001F468\u200D\U0001F466",
+			"-<\U0001F468\u200D\U0001F469> +<\U0001F468\u200D\U0001F466>"},
+		{"Flags", "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7", "\U0001F1EA\U0001F1EB",
+			"-<\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7> +<\U0001F1EA\U0001F1EB>"},
+		{"Equal", "e\u0301", "e\u0301", "=<e\u0301>"},
+	} {
+		assertEquals(x.name, diffList(x.diffs), c.DiffMain(x.text1, x.text2), t)
+	}
+
+	// Without Graphemes, diffs split clusters.
+	assertEquals("Runes", diffList("=<cafe> +<\u0301>"), DiffMain("cafe", "cafe\u0301", false, 0), t)
+
+	diffs := diffList("-<e\u0301> +<e\u0300>")
+	assertEquals("Merge keeps clusters", diffList("-<e\u0301> +<e\u0300>"), c.CleanupMerge(&diffs), t)
+	diffs = diffList("-<e\u0301> +<e\u0300>")
+	assertEquals("Merge without Graphemes", diffList("=<e> -<\u0301> +<\u0300>"), diffs.CleanupMerge(), t)
+
+	diffs = diffList("=<a> +<e\u0301b> =<e\u0302>")
+	assertEquals("Lossless shift", diffList("=<a> +<e\u0301b> =<e\u0302>"), c.CleanupSemanticLossless(&diffs), t)
+	diffs = diffList("=<a> +<e\u0301b> =<e\u0302>")
+	assertEquals("Lossless shift without Graphemes", diffList("=<ae> +<\u0301be> =<\u0302>"), diffs.CleanupSemanticLossless(), t)
+
+	diffs = diffList("-<xe\u0301> +<e\u0301\u0302y>")
+	assertEquals("Overlap", diffList("-<xe\u0301> +<e\u0301\u0302y>"), c.CleanupSemantic(&diffs), t)
+
+	// Multi-byte characters are shifted as a whole.
+	diffs = diffList("=<x> +<\u00E9a> =<\u00E9b>")
+	assertEquals("Multi-byte shift", diffList("=<x\u00E9> +<a\u00E9> =<b>"), diffs.CleanupSemanticLossless(), t)
+
+	text1 := strings.Repeat("line e\u0301\n", 60)
+	text2 := strings.Replace(text1, "e\u0301\nline", "e\u0300\nline", 1)
+	c.CheckLines = true
+	diffs = c.DiffMain(text1, text2)
+	assertEquals("Line mode: text1", text1, diffs.Text1(), t)
+	assertEquals("Line mode: text2", text2, diffs.Text2(), t)
+	for _, d := range diffs {
+		if graphemeBreak(firstRune(d.Text)) == gcbExtend {
+			t.Errorf("Line mode: cluster split: %+q", d.Text)
+		}
+	}
+}
+
+func TestConfigNormalize(t *testing.T) {
+	// A stand-in for a normalization form, composing some characters,
+	// and decomposing a ligature.
+	nfc := strings.NewReplacer("e\u0301", "\u00E9", "e\u0300", "\u00E8", "a\u0308", "\u00E4", "\uFB01", "fi")
+	c := &Config{Normalize: nfc.Replace}
+	for _, x := range []struct{ name, text1, text2, diffs string }{
+		{"Equal", "caf\u00E9", "caf\u00E9", "=<caf\u00E9>"},
+		{"Forms differ only", "caf\u00E9", "cafe\u0301", "=<caf> -<\u00E9> +<e\u0301>"},
+		{"Text changed", "cafe\u0301 au lait", "caf\u00E9 au th\u00E9",
+			"=<caf> -<e\u0301> +<\u00E9> =< au > -<lai> =<t> +<h\u00E9>"},
+		{"Forms differ next to an edit", "cafe\u0301x", "caf\u00E9y", "=<caf> -<e\u0301x> +<\u00E9y>"},
+		{"Accent changed", "cafe\u0301", "caf\u00E8", "=<caf> -<e\u0301> +<\u00E8>"},
+		{"Edit widened", "\uFB01x", "fax", "-<\uFB01> +<fa> =<x>"},
+		{"Edits merged", "\uFB01\uFB01", "fafa", "-<\uFB01\uFB01> +<fafa>"},
+		{"Empty", "", "a\u0308", "+<a\u0308>"},
+	} {
+		diffs := c.DiffMain(x.text1, x.text2)
+		assertEquals(x.name, diffList(x.diffs), diffs, t)
+		assertEquals(x.name+": text1", x.text1, diffs.Text1(), t)
+		assertEquals(x.name+": text2", x.text2, diffs.Text2(), t)
+	}
+
+	// Segment boundaries of the texts that do not match.
+	diffs := c.DiffMain("e\n\uFB01\u0301\u00E9\u0301\n", "\nia")
+	assertEquals("Unaligned segments: text1", "e\n\uFB01\u0301\u00E9\u0301\n", diffs.Text1(), t)
+	assertEquals("Unaligned segments: text2", "\nia", diffs.Text2(), t)
+
+	c.Ignore = IgnoreCase
+	diffs = c.DiffMain("Caf\u00E9", "cAfe\u0301")
+	assertEquals("Ignore case", diffList("=<Caf> -<\u00E9> +<e\u0301>"), diffs, t)
+	diffs = c.DiffLines("a\ncaf\u00E9\nb\n", "A\ncafe\u0301\nb\n")
+	assertEquals("Lines", diffList("=<a\n> -<caf\u00E9\n> +<cafe\u0301\n> =<b\n>"), diffs, t)
+}
+
+func TestConfigIgnore(t *testing.T) {
+	for _, x := range []struct {
+		name         string
+		ignore       Ignore
+		text1, text2 string
+		diffs        string
+	}{
+		{"Case", IgnoreCase, "Hello World", "hello WORLD!", "=<Hello World> +<!>"},
+		{"Case, non-ASCII", IgnoreCase, "\u00C4rger", "\u00E4rger", "=<\u00C4rger>"},
+		{"Space change", IgnoreSpaceChange, "a  b\tc \n", "a b c\n", "=<a  b\tc \n>"},
+		{"Space change, added", IgnoreSpaceChange, "ab", "a b", "=<a> +< > =<b>"},
+		{"All space", IgnoreAllSpace, "a b c", "abc", "=<a b c>"},
+		{"Blank lines", IgnoreBlankLines, "a\nb\n", "a\n\n  \nb\n", "=<a\nb\n>"},
+		{"Blank lines, changed", IgnoreBlankLines, "a\nb\n", "a\n\nc\n", "=<a\n> -<b> +<\nc> =<\n>"},
+		{"CR", IgnoreCR, "a\r\nb\r\n", "a\nb\nc", "=<a\r\nb\r\n> +<c>"},
+		{"Not ignored", IgnoreCR, "A b", "a  b", "-<A> +<a > =< b>"},
+	} {
+		c := &Config{Ignore: x.ignore}
+		diffs := c.DiffMain(x.text1, x.text2)
+		assertEquals(x.name, diffList(x.diffs), diffs, t)
+		assertEquals(x.name+": text1", x.text1, diffs.Text1(), t)
+	}
+
+	for _, x := range []struct {
+		name         string
+		ignore       Ignore
+		text1, text2 string
+		diffs        string
+	}{
+		{"Lines", 0, "a\nb\nc\n", "a\nB\nc\n", "=<a\n> -<b\n> +<B\n> =<c\n>"},
+		{"Lines, case", IgnoreCase, "a\nb\nc\n", "a\nB\nd", "=<a\nb\n> -<c\n> +<d>"},
+		{"Lines, space, CR", IgnoreSpaceChange | IgnoreCR, "a  b\r\nc\r\n", "a b \nc\n", "=<a  b\r\nc\r\n>"},
+		{"Lines, blank", IgnoreBlankLines, "a\n\nb\n", "a\nb\n\n", "=<a\n\nb\n>"},
+	} {
+		c := &Config{Ignore: x.ignore}
+		diffs := c.DiffLines(x.text1, x.text2)
+		assertEquals(x.name, diffList(x.diffs), diffs, t)
+		assertEquals(x.name+": text1", x.text1, diffs.Text1(), t)
+	}
+}
+
+func TestConfigMasks(t *testing.T) {
+	c := &Config{Masks: []Mask{
+		{"time", regexp.MustCompile(`[0-9]{2}:[0-9]{2}:[0-9]{2}`)},
+		{"addr", regexp.MustCompile(`0x[0-9a-f]+`)},
+	}}
+	text1 := "12:00:01 alloc 0x1f00 ok\n12:00:02 free 0x1f00\n"
+	text2 := "13:45:10 alloc 0x2a08 ok\n13:45:11 free 0x2a08 twice\n"
+
+	diffs := c.DiffMain(text1, text2)
+	assertEquals("DiffMain", diffList("=<12:00:01 alloc 0x1f00 ok\n12:00:02 free 0x1f00> +< twice> =<\n>"), diffs, t)
+
+	diffs, masked := c.DiffMasked(text1, text2)
+	assertEquals("DiffMasked", diffList("-<12:00:01> +<13:45:10> =< alloc > -<0x1f00> +<0x2a08> =< ok\n> "+
+		"-<12:00:02> +<13:45:11> =< free > -<0x1f00> +<0x2a08> +< twice> =<\n>"), diffs, t)
+	assertEquals("Text1", text1, diffs.Text1(), t)
+	assertEquals("Text2", text2, diffs.Text2(), t)
+	assertEquals("Masked", "[{0 time} {3 addr} {6 time} {9 addr}]", fmt.Sprint(masked), t)
+
+	// Spans matched by different masks are not equal.
+	diffs, masked = c.DiffMasked("at 12:00:00", "at 0xff")
+	assertEquals("Different masks", diffList("=<at > -<12:00:00> +<0xff>"), diffs, t)
+	assertEquals("Different masks: masked", 0, len(masked), t)
+
+	// Runes used as placeholders of masks are not matched by these.
+	diffs = c.DiffMain("at \U000F0000", "at 12:00:00")
+	assertEquals("Placeholder rune", diffList("=<at > -<\U000F0000> +<12:00:00>"), diffs, t)
+	diffs = c.DiffMain("\U0010FFFF\U000F0001 12:00:00", "\U0010FFFF0xff 12:00:01")
+	assertEquals("Escape rune", diffList("=<\U0010FFFF> -<\U000F0001> +<0xff> =< 12:00:00>"), diffs, t)
+	diffs = c.DiffMain("\U000F0000\U0010FFFF", "\U000F0000\U0010FFFF")
+	assertEquals("Private use runes", diffList("=<\U000F0000\U0010FFFF>"), diffs, t)
+
+	// Masks combine with Ignore.
+	c.Ignore = IgnoreCase
+	diffs, masked = c.DiffMasked("ID 0xab", "id 0xcd")
+	assertEquals("Ignore", diffList("=<ID > -<0xab> +<0xcd>"), diffs, t)
+	assertEquals("Ignore: masked", 1, len(masked), t)
+}
+
+func TestConfigScorer(t *testing.T) {
+	for _, x := range []struct {
+		name         string
+		scorer       Scorer
+		input, plain string
+		result       string
+	}{
+		{"Code",
+			CodeScorer,
+			"=<a()\nif> +< p {\n\tb()\n}\nif> =< p {\n\tc()\n}\n>",
+			"=<a()\nif p {\n\t> +<b()\n}\nif p {\n\t> =<c()\n}\n>",
+			"=<a()\n> +<if p {\n\tb()\n}\n> =<if p {\n\tc()\n}\n>",
+		},
+		{"CJK",
+			CJKScorer,
+			"=<私は東> +<京に行き東> =<京で働く>",
+			"=<私は東京> +<に行き東京> =<で働く>",
+			"=<私は> +<東京に行き> =<東京で働く>",
+		},
+		{"Identifier",
+			IdentifierScorer,
+			"=<fooBarB> +<azB> =<ax>",
+			"=<fooBarBa> +<zBa> =<x>",
+			"=<fooBar> +<Baz> =<Bax>",
+		},
+	} {
+		diffs := diffList(x.input)
+		assertEquals(x.name+": default", diffList(x.plain), diffs.CleanupSemanticLossless(), t)
+		diffs = diffList(x.input)
+		c := &Config{Scorer: x.scorer}
+		assertEquals(x.name, diffList(x.result), c.CleanupSemanticLossless(&diffs), t)
+	}
+}
+
+func TestDiffCleanupIndentHeuristic(t *testing.T) {
+	f := func(desc string) Diffs {
+		diffs := diffList(desc)
+		return diffs.CleanupIndentHeuristic()
+	}
+	for _, x := range []struct{ name, input, result string }{
+		{"Null case", "", ""},
+		{
+			"Function inserted",
+			"=<func a() {\n\tx()\n> +<}\n\nfunc b() {\n\ty()\n> =<}\n\nfunc c() {\n\tz()\n}\n>",
+			"=<func a() {\n\tx()\n}\n\n> +<func b() {\n\ty()\n}\n\n> =<func c() {\n\tz()\n}\n>",
+		}, {
+			"Block deleted",
+			"=<if a {\n\tx()\n> -<}\nif b {\n\tx()\n> =<}\nend()\n>",
+			"=<if a {\n\tx()\n}\n> -<if b {\n\tx()\n}\n> =<end()\n>",
+		}, {
+			"Equality consumed",
+			"=<if a {\n\tx()\n> +<}\nif b {\n\tx()\n> =<}\n>",
+			"=<if a {\n\tx()\n}\n> +<if b {\n\tx()\n}\n>",
+		}, {
+			"Replacement kept",
+			"=<}\n> -<}\n> +<)\n> =<}\n>",
+			"=<}\n> -<}\n> +<)\n> =<}\n>",
+		},
+		{"Parts of lines", "=<a(> +<b> =<b)\n>", "=<a(> +<b> =<b)\n>"},
+		{"Equality starting mid-line", "=<x> -<y> =<\n> +<a\n> =<a\n>", "=<x> -<y> =<\n> +<a\n> =<a\n>"},
+		{"Equality ending mid-line", "=<x\n> +<a\n> =<a\nb> +<c> =<\n>", "=<x\n> +<a\n> =<a\nb> +<c> =<\n>"},
+	} {
+		assertEquals(x.name, diffList(x.result), f(x.input), t)
+	}
+
+	// Line diffs of random texts keep their texts.
+	r := rand.New(rand.NewSource(1))
+	randText := func() string {
+		b := make([]byte, r.Intn(12))
+		for i := range b {
+			b[i] = "ab\n"[r.Intn(3)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 3000; i++ {
+		text1, text2 := randText(), randText()
+		diffs := DiffMain(text1, text2, false, 0)
+		diffs.CleanupIndentHeuristic()
+		if diffs.Text1() != text1 || diffs.Text2() != text2 {
+			t.Fatalf("%q, %q: texts changed: %v", text1, text2, diffs)
+		}
+	}
+
+	text1 := "func a() {\n\tx()\n}\n\nfunc c() {\n\tz()\n}\n"
+	text2 := "func a() {\n\tx()\n}\n\nfunc b() {\n\tx()\n}\n\nfunc c() {\n\tz()\n}\n"
+	diffs := new(Config).DiffLines(text1, text2)
+	assertEquals("DiffLines", diffList("=<func a() {\n\tx()\n}\n\n> +<func b() {\n\tx()\n}\n\n> =<func c() {\n\tz()\n}\n>"), diffs.CleanupIndentHeuristic(), t)
+}
+
+func TestDiffCleanupCost(t *testing.T) {
+	for _, x := range []struct{ name, input, result string }{
+		{"Null case", "", ""},
+		{"Short equality", "-<ab> +<12> =<x> -<cd> +<34>", "-<abxcd> +<12x34>"},
+		{"Long equality", "-<ab> +<12> =<this is long> -<cd> +<34>", "-<ab> +<12> =<this is long> -<cd> +<34>"},
+		{"Deletions only", "=<ab> -<c> =<d> -<e> =<fg>", "=<ab> -<cde> +<d> =<fg>"},
+		{"Chain", "-<a> =<b> +<c> =<d> -<e>", "-<abde> +<bcd>"},
+	} {
+		diffs := diffList(x.input)
+		cost := DeltaCost.Cost(diffs)
+		diffs.CleanupCost(DeltaCost)
+		assertEquals(x.name, diffList(x.result), diffs, t)
+		assertTrue(x.name+": cost", DeltaCost.Cost(diffs) <= cost, t)
+		if len(diffs) != 0 {
+			assertEquals(x.name+": delta", len(diffs.ToDelta())+1, DeltaCost.Cost(diffs), t)
+		}
+	}
+
+	// Inserting digits is expensive.
+	m := &CostModel{Equality: 1, Weight: RuneWeight(func(op int, r rune) int {
+		if op == Insert && unicode.IsDigit(r) {
+			return 10
+		}
+		return 0
+	})}
+	diffs := diffList("-<a> +<b> =<x> -<c> +<d> =<1> -<e> +<f>")
+	assertEquals("Model", diffList("-<axc> +<bxd> =<1> -<e> +<f>"), diffs.CleanupCost(m), t)
+	assertEquals("Model: cost", 1, m.Cost(diffs), t)
+}
+
+func TestDiffAlignLines(t *testing.T) {
+	diffs := diffList("=<one\ntw> -<o> +<ice> =<\nthree\n> +<four\n> =<five> -<\nsix>")
+	lines := diffs.AlignLines()
+	want := []struct{ diff, detail string }{
+		{"=<one\n>", ""},
+		{"-<two\n>", "=<tw> -<o> +<ice> =<\n>"},
+		{"+<twice\n>", "=<tw> -<o> +<ice> =<\n>"},
+		{"=<three\n>", ""},
+		{"+<four\n>", "+<four\n>"},
+		{"-<five\nsix>", "=<five> -<\nsix>"},
+		{"+<five>", "=<five> -<\nsix>"},
+	}
+	assertEquals("Count", len(want), len(lines), t)
+	for i, l := range lines {
+		if i < len(want) {
+			name := "Line " + strconv.Itoa(i)
+			assertEquals(name, diffList(want[i].diff), Diffs{l.Diff}, t)
+			assertEquals(name+": detail", diffList(want[i].detail), l.Detail, t)
+		}
+	}
+
+	assertEquals("CleanupLines", diffList("=<one\n> -<two\n> +<twice\n> =<three\n> +<four\n> -<five\nsix> +<five>"), diffs.CleanupLines(), t)
+}
+
+func TestDiffDetectMoves(t *testing.T) {
+	moves := func(moves []Move) string {
+		var b strings.Builder
+		for _, m := range moves {
+			fmt.Fprintf(&b, "%d>%d:%d ", m.From, m.To, m.Distance)
+		}
+		return b.String()
+	}
+	diffs := diffList("-<func a() {}\n> =<x\n> -<y\n> +<z\n> =<w\n> +<func a() {}\n>")
+	assertEquals("Identical", "0>5:0 ", moves(diffs.DetectMoves(3, 0)), t)
+	assertEquals("Too short", "", moves(diffs.DetectMoves(20, 0)), t)
+
+	diffs = diffList("-<hello world> =<, and> +<hello, world>")
+	assertEquals("Dissimilar", "", moves(diffs.DetectMoves(1, 0)), t)
+	assertEquals("Similar", "0>2:1 ", moves(diffs.DetectMoves(1, 0.1)), t)
+
+	diffs = diffList("=<a> -<same text> +<same text> =<b>")
+	assertEquals("Adjacent", "", moves(diffs.DetectMoves(1, 0)), t)
+
+	diffs = diffList("-<abc> =<x> +<abd> =<y> +<abc>")
+	assertEquals("Most similar preferred", "0>4:0 ", moves(diffs.DetectMoves(1, 0.5)), t)
+
+	// The bounded distance matches the full computation.
+	full := func(r1, r2 []rune) int {
+		prev := make([]int, len(r2)+1)
+		for j := range prev {
+			prev[j] = j
+		}
+		for i := range r1 {
+			cur := []int{i + 1}
+			for j := range r2 {
+				d := prev[j]
+				if r1[i] != r2[j] {
+					d++
+				}
+				cur = append(cur, min(d, min(prev[j+1], cur[j])+1))
+			}
+			prev = cur
+		}
+		return prev[len(r2)]
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		r1, r2 := []rune(strconv.FormatInt(r.Int63n(1e6), 3)), []rune(strconv.FormatInt(r.Int63n(1e6), 3))
+		want := full(r1, r2)
+		for limit := max(len(r1)-len(r2), len(r2)-len(r1)); limit <= len(r1)+len(r2); limit++ {
+			have := levenshtein(r1, r2, limit)
+			if want <= limit && have != want || want > limit && have != limit+1 {
+				t.Fatalf("%s, %s, limit %d: distance %d, want %d", string(r1), string(r2), limit, have, want)
+			}
+		}
+	}
+}
+
+func TestDiffRenderMoves(t *testing.T) {
+	diffs := diffList("-<one\n> =<two\n> +<one\n>")
+	moves := diffs.DetectMoves(1, 0)
+
+	r := &HTMLRenderer{Insert: Markup{Tag: "ins"}, Delete: Markup{Tag: "del"}, MoveDelete: Markup{Tag: "del", Class: "moved"}, Moves: moves}
+	assertEquals("HTML", `<del id="move0-from" class="moved"><a href="#move0-to">one
+</a></del>two
+<a id="move0-to" href="#move0-from">one
+</a>`, renderString(r, diffs), t)
+
+	assertEquals("Term", ansiMagenta+"-one"+ansiReset+"\n two\n"+ansiBlue+"+one"+ansiReset+"\n",
+		renderString(&TermRenderer{Color: true, Moves: moves}, diffs), t)
+	assertEquals("Term, unified", ansiCyan+"@@ -1 +0,0 @@"+ansiReset+"\n"+ansiMagenta+"-one"+ansiReset+"\n"+
+		ansiCyan+"@@ -2,0 +2 @@"+ansiReset+"\n"+ansiBlue+"+one"+ansiReset+"\n",
+		renderString(&TermRenderer{Color: true, Unified: true, Moves: moves}, diffs), t)
+}
+
+func TestConfigDiffAnchored(t *testing.T) {
+	c := &Config{}
+	text1 := "a\nb\nc\nd\n"
+	text2 := "c\nd\na\nb\n"
+	assertEquals("Anchored", diffList("+<c\nd\n> =<a\nb\n> -<c\nd\n>"), c.DiffAnchored(text1, text2, []string{"a\n"}), t)
+	assertEquals("Anchored, other side", diffList("-<a\nb\n> =<c\nd\n> +<a\nb\n>"), c.DiffAnchored(text1, text2, []string{"c\n"}), t)
+	assertEquals("Crossing anchor ignored", diffList("+<c\nd\n> =<a\nb\n> -<c\nd\n>"), c.DiffAnchored(text1, text2, []string{"a\n", "c\n"}), t)
+	assertEquals("Missing anchor ignored", c.DiffMain(text1, text2), c.DiffAnchored(text1, text2, []string{"x\n"}), t)
+	assertEquals("Ambiguous anchor ignored", c.DiffMain("aXbX", "Xab"), c.DiffAnchored("aXbX", "Xab", []string{"X"}), t)
+
+	assertEquals("At", diffList("+<bc> =<a> -<bc>"), c.DiffAt("abc", "bca", []Anchor{{1, 3}}), t)
+	assertEquals("At, joined", diffList("-<ab> +<xy>"), c.DiffAt("ab", "xy", []Anchor{{1, 1}}), t)
+	assertEquals("At, invalid anchors ignored", diffList("=<ab> +<c>"), c.DiffAt("ab", "abc", []Anchor{{1, 1}, {0, 0}, {3, 3}}), t)
+	assertEquals("At, within rune", c.DiffMain("äb", "äc"), c.DiffAt("äb", "äc", []Anchor{{1, 1}}), t)
+}
+
+func TestDiffAlgebra(t *testing.T) {
+	diffs := diffList("=<a> -<b> +<c> =<d>")
+	assertEquals("Invert", diffList("=<a> +<b> -<c> =<d>"), diffs.Invert(), t)
+
+	texts := []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"The quick red fox jumped over a lazy dog!",
+		"A quick red fox jumped over the dog, twice.",
+		"",
+		"Über den Wolken muß die Freiheit wohl grenzenlos sein.",
+	}
+	for _, t1 := range texts {
+		for _, t2 := range texts {
+			for _, t3 := range texts {
+				a := DiffMain(t1, t2, false, 0)
+				b := DiffMain(t2, t3, false, 0)
+				c, err := Compose(a, b)
+				ok := err == nil && c.Text1() == t1 && c.Text2() == t3
+				assertTrue(fmt.Sprintf("Compose %q %q %q", t1, t2, t3), ok, t)
+			}
+		}
+	}
+
+	_, err := Compose(DiffMain("abc", "abd", false, 0), DiffMain("abx", "aby", false, 0))
+	e, ok := err.(*MismatchError)
+	assertTrue("Compose mismatch", ok && e.Offset == 2, t)
+
+	base := "The cat sat on the mat."
+	a := DiffMain(base, "The big cat sat on the mat.", false, 0)
+	b := DiffMain(base, "The cat sat on the red mat!", false, 0)
+	a2, b2, err := Transform(a, b)
+	assertTrue("Transform", err == nil, t)
+	ab, _ := a2.Apply(b.Text2())
+	ba, _ := b2.Apply(a.Text2())
+	assertEquals("Transform a after b", "The big cat sat on the red mat!", ab, t)
+	assertEquals("Transform b after a", "The big cat sat on the red mat!", ba, t)
+
+	a = diffList("=<x> +<A> =<y>")
+	b = diffList("=<x> +<B> =<y>")
+	a2, b2, _ = Transform(a, b)
+	ab, _ = a2.Apply(b.Text2())
+	ba, _ = b2.Apply(a.Text2())
+	assertEquals("Concurrent insertions a after b", "xABy", ab, t)
+	assertEquals("Concurrent insertions b after a", "xABy", ba, t)
+
+	for _, t1 := range texts {
+		for _, t2 := range texts {
+			a := DiffMain(texts[0], t1, false, 0)
+			b := DiffMain(texts[0], t2, false, 0)
+			a2, b2, err := Transform(a, b)
+			ab, err1 := a2.Apply(t2)
+			ba, err2 := b2.Apply(t1)
+			ok := err == nil && err1 == nil && err2 == nil && ab == ba
+			assertTrue(fmt.Sprintf("Transform %q %q", t1, t2), ok, t)
+		}
+	}
+	_, _, err = Transform(DiffMain("abc", "abd", false, 0), DiffMain("abx", "aby", false, 0))
+	assertTrue("Transform mismatch", err != nil, t)
+}
+
 func TestDiffLevenshtein(t *testing.T) {
 	diffs := diffList("-<abc> +<1234> =<xyz>")
 	assertEquals("Levenshtein with trailing equality", 4, diffs.Levenshtein(), t)
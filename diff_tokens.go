@@ -0,0 +1,70 @@
+// Diff Match and Patch – diffs of token sequences
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A TokenDiff is an operation on a run of tokens.
+type TokenDiff struct {
+	Op     int
+	Tokens []string
+}
+
+type TokenDiffs []TokenDiff
+
+// Find the differences between two sequences of tokens, like words,
+// lines, or the elements of a list, each treated as an atomic unit.
+// Tokens are equal if their strings are. The timeout is handled the
+// way DiffMain handles it.
+func DiffTokens(tokens1, tokens2 []string, timeout time.Duration) TokenDiffs {
+	m := newLineMunger()
+	chars1 := m.tokensToChars(tokens1)
+	chars2 := m.tokensToChars(tokens2)
+	diffs := DiffMain(chars1, chars2, false, timeout)
+
+	tdiffs := make(TokenDiffs, len(diffs))
+	pos1, pos2 := 0, 0
+	for i, d := range diffs {
+		n := utf8.RuneCountInString(d.Text)
+		var tokens []string
+		switch d.Op {
+		case Insert:
+			tokens = tokens2[pos2 : pos2+n]
+			pos2 += n
+		case Delete:
+			tokens = tokens1[pos1 : pos1+n]
+			pos1 += n
+		case Equal:
+			tokens = tokens1[pos1 : pos1+n]
+			pos1 += n
+			pos2 += n
+		}
+		tdiffs[i] = TokenDiff{d.Op, tokens}
+	}
+	return tdiffs
+}
+
+// Convert the list into a Diff list, by concatenating the tokens of
+// each TokenDiff. Empty results are left out.
+func (tdiffs TokenDiffs) Diffs() Diffs {
+	diffs := make(Diffs, 0, len(tdiffs))
+	for _, td := range tdiffs {
+		text := strings.Join(td.Tokens, "")
+		switch n := len(diffs); {
+		case text == "":
+		case n != 0 && diffs[n-1].Op == td.Op:
+			diffs[n-1].Text += text
+		default:
+			diffs.add(td.Op, text)
+		}
+	}
+	return diffs
+}
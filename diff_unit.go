@@ -0,0 +1,129 @@
+// Diff Match and Patch – units of measurement
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package dmp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// A Unit selects how positions within a text, and lengths
+// of parts of it, are measured.
+type Unit int
+
+const (
+	Bytes Unit = iota
+	Runes
+	Lines // positions are line indices
+	UTF16 // UTF-16 code units, as used by JavaScript and Java
+)
+
+// Count the units in s. For Lines, this is the number of line breaks,
+// so that adding up the counts of consecutive parts of a text
+// yields the index of the line a position is on.
+func (u Unit) count(s string) int {
+	switch u {
+	case Runes:
+		return utf8.RuneCountInString(s)
+	case Lines:
+		return strings.Count(s, "\n")
+	case UTF16:
+		n := 0
+		for _, r := range s {
+			n += utf16Len(r)
+		}
+		return n
+	}
+	return len(s)
+}
+
+// Return the number of UTF-16 code units needed to encode r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+// Return the byte length of the first n units of s, or len(s),
+// if s is shorter. For Lines, the prefix ends after the n-th line break.
+func (u Unit) prefixLen(s string, n int) int {
+	switch u {
+	case Runes:
+		for i := range s {
+			if n == 0 {
+				return i
+			}
+			n--
+		}
+	case Lines:
+		i := 0
+		for ; n > 0; n-- {
+			j := strings.IndexByte(s[i:], '\n')
+			if j == -1 {
+				return len(s)
+			}
+			i += j + 1
+		}
+		return i
+	case UTF16:
+		for i, r := range s {
+			if n <= 0 {
+				return i
+			}
+			n -= utf16Len(r)
+		}
+	default:
+		if n < len(s) {
+			return len(s) - len(utf8SliceRightX(s, n))
+		}
+	}
+	return len(s)
+}
+
+// Return the byte length of the last n units of s, or len(s),
+// if s is shorter. For Lines, the suffix starts after the
+// n-th line break counted from the end of s.
+func (u Unit) suffixLen(s string, n int) int {
+	switch u {
+	case Runes:
+		i := len(s)
+		for ; n > 0 && i > 0; n-- {
+			_, size := utf8.DecodeLastRuneInString(s[:i])
+			i -= size
+		}
+		return len(s) - i
+	case UTF16:
+		i := len(s)
+		for n > 0 && i > 0 {
+			r, size := utf8.DecodeLastRuneInString(s[:i])
+			i -= size
+			n -= utf16Len(r)
+		}
+		return len(s) - i
+	case Lines:
+		i := len(s)
+		for ; n > 0; n-- {
+			j := strings.LastIndexByte(s[:i], '\n')
+			if j == -1 {
+				return len(s)
+			}
+			i = j
+		}
+		if i == len(s) {
+			return 0
+		}
+		return len(s) - i - 1
+	}
+	if n <= 0 {
+		return 0
+	}
+	if n < len(s) {
+		return len(utf8SliceRight(s, len(s)-n))
+	}
+	return len(s)
+}
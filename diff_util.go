@@ -9,7 +9,7 @@ package dmp
 
 import (
 	. "github.com/knieriem/dmp/rstring"
-	"html"
+	"strconv"
 	"strings"
 )
 
@@ -49,21 +49,9 @@ func (diffs Diffs) XIndex(loc1 int) (loc2 int) {
 }
 
 // Convert a Diff list into a pretty HTML report.
-func (diffs Diffs) PrettyHTML() (s string) {
-	r := strings.NewReplacer("\n", "&para;<br>")
-	for _, d := range diffs {
-		text := html.EscapeString(string(d.Text))
-		text = r.Replace(text)
-		switch d.Op {
-		case Insert:
-			s += `<ins style="background:#e6ffe6;">` + text + "</ins>"
-		case Delete:
-			s += `<del style="background:#ffe6e6;">` + text + "</del>"
-		case Equal:
-			s += "<span>" + text + "</span>"
-		}
-	}
-	return
+// Use an HTMLRenderer to customize the markup.
+func (diffs Diffs) PrettyHTML() string {
+	return renderString(&prettyHTML, diffs)
 }
 
 // Compute and return the source text (all equalities and deletions).
@@ -86,6 +74,47 @@ func (diffs Diffs) Text2() (dest string) {
 	return
 }
 
+// Apply the Diff list to text1, and return the resulting text2.
+// Each equality and deletion is verified against text1; if one
+// doesn't match, or if text1 is longer than the Diff list expects,
+// a *MismatchError is returned.
+func (diffs Diffs) Apply(text1 string) (text2 string, err error) {
+	var b strings.Builder
+	pos := 0
+	for i, d := range diffs {
+		if d.Op == Insert {
+			b.WriteString(d.Text)
+			continue
+		}
+		if !strings.HasPrefix(text1[pos:], d.Text) {
+			off := pos + len(commonPrefix(text1[pos:], d.Text))
+			return "", &MismatchError{Offset: off, Index: i}
+		}
+		if d.Op == Equal {
+			b.WriteString(d.Text)
+		}
+		pos += len(d.Text)
+	}
+	if pos != len(text1) {
+		return "", &MismatchError{Offset: pos, Index: -1}
+	}
+	return b.String(), nil
+}
+
+// A MismatchError reports the byte offset in a text where it
+// differs from what a Diff list expects.
+type MismatchError struct {
+	Offset int
+	Index  int // index of the Diff not matching, or -1, if the text is too long
+}
+
+func (e *MismatchError) Error() string {
+	if e.Index == -1 {
+		return "dmp: unexpected text at offset " + strconv.Itoa(e.Offset)
+	}
+	return "dmp: diff " + strconv.Itoa(e.Index) + " does not match text at offset " + strconv.Itoa(e.Offset)
+}
+
 // Compute the Levenshtein distance – the number of inserted, deleted or
 // substituted characters.
 func (diffs Diffs) Levenshtein() (levenshtein int) {
@@ -0,0 +1,276 @@
+// Diff Match and Patch – differential synchronization
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package diffsync implements Neil Fraser's differential synchronization,
+in its guaranteed delivery variant, which keeps a text in sync between
+a server and any number of clients editing it concurrently.
+
+Each side of a connection keeps a Session, containing a shadow of the
+text as it has last been synchronized. Local changes are found by
+diffing the shadow against the Document, and sent as deltas, in the
+format of Diffs.ToDelta, so that the peer can be a browser running
+one of the other ports of diff_match_patch. Received deltas are
+applied to the shadow, and merged into the Document, which may have
+changed in the meantime.
+
+Edits are numbered. They are kept on a stack, and resent with each
+message, until the peer has acknowledged them. A backup of the shadow
+allows the server to recover if its last message has been lost.
+
+A client initiates an exchange by sending the Message returned by
+Flush; the server answers it using Handle, and the client passes the
+answer to Receive. The client should send its next message only after
+it has received the answer, or a timeout has passed. Messages may get
+lost, duplicated, or arrive out of order.
+
+If the sessions get out of sync, the server resends its full text,
+which replaces the client's text, and both sessions start anew.
+*/
+package diffsync
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/knieriem/dmp"
+)
+
+// ErrOutOfSync reports that a message does not fit the state of
+// a session. Changes not synchronized yet are lost; the sessions
+// are reset to the server's text.
+var ErrOutOfSync = errors.New("diffsync: shadows out of sync")
+
+// A Message is exchanged between client and server.
+type Message struct {
+	Seq     int    `json:"seq,omitempty"`     // number of a client's message
+	ReplyTo int    `json:"replyTo,omitempty"` // number of the client's message answered by the server
+	Ack     int    `json:"ack"`               // number of the receiver's edits applied by the sender
+	Edits   []Edit `json:"edits,omitempty"`
+
+	// If Reset is set, a client requests the server's full text,
+	// or the server sends it, as Text, to start anew.
+	Reset bool   `json:"reset,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// An Edit is a change of the sender's shadow. Version is the number
+// of edits preceding it.
+type Edit struct {
+	Version int    `json:"v"`
+	Delta   string `json:"d"`
+}
+
+// A Document holds the text edited locally, and synchronized by
+// one or more Sessions. It is safe for concurrent use.
+type Document struct {
+	mu   sync.Mutex
+	text string
+}
+
+func NewDocument(text string) *Document {
+	return &Document{text: text}
+}
+
+func (d *Document) Text() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.text
+}
+
+// Replace the text, e.g. after it has been edited locally.
+func (d *Document) SetText(text string) {
+	d.mu.Lock()
+	d.text = text
+	d.mu.Unlock()
+}
+
+// Merge diffs, a change of shadow made by the peer, into the text,
+// which might have been changed locally since shadow was taken from it.
+func (d *Document) merge(shadow string, diffs dmp.Diffs) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.text == shadow {
+		d.text = diffs.Text2()
+		return nil
+	}
+	local := dmp.DiffMain(shadow, d.text, false, 0)
+	remote, _, err := dmp.Transform(diffs, local)
+	if err != nil {
+		return err
+	}
+	d.text = remote.Text2()
+	return nil
+}
+
+// A Session synchronizes a Document with a peer. It is not safe
+// for concurrent use.
+type Session struct {
+	doc    *Document
+	shadow string
+
+	localVersion  int // number of edits sent
+	remoteVersion int // number of edits received
+
+	backup        string
+	backupVersion int
+
+	stack []Edit // edits not acknowledged yet
+
+	seq     int // client: number of the last message sent; server: of the last one handled
+	replied int // client: number of the last message answered
+
+	outOfSync bool // client: request the server's text
+}
+
+// Create a Session synchronizing doc. The peer's session must start
+// with the same text.
+func NewSession(doc *Document) *Session {
+	s := &Session{doc: doc}
+	s.reset(doc.Text())
+	return s
+}
+
+// Start anew, with the shadow set to text.
+func (s *Session) reset(text string) {
+	s.shadow, s.backup = text, text
+	s.localVersion, s.remoteVersion, s.backupVersion = 0, 0, 0
+	s.stack = nil
+	s.outOfSync = false
+}
+
+// Return the message a client sends to the server, carrying the local
+// changes made since the last call, and the edits not acknowledged yet.
+// If the last answer has not fit the session, the message requests
+// the server's text instead.
+func (s *Session) Flush() Message {
+	s.seq++
+	if s.outOfSync {
+		return Message{Seq: s.seq, Reset: true}
+	}
+	m := s.flush()
+	m.Seq = s.seq
+	return m
+}
+
+// Process the server's answer to a client's message. Answers to
+// earlier messages, and duplicates, are ignored. If the answer carries
+// the server's text, it replaces the text of the Document. If the answer
+// does not fit the session, ErrOutOfSync is returned, and the next
+// message requests the server's text.
+func (s *Session) Receive(m Message) error {
+	if m.ReplyTo != s.seq || m.ReplyTo == s.replied {
+		return nil
+	}
+	s.replied = m.ReplyTo
+	if m.Reset {
+		s.doc.SetText(m.Text)
+		s.reset(m.Text)
+		return nil
+	}
+	if err := s.receive(&m); err != nil {
+		s.outOfSync = true
+		return err
+	}
+	return nil
+}
+
+// Process a client's message on the server, and return the answer.
+// If m is outdated, or a duplicate, it is ignored, and reply is nil.
+// If m does not fit the session, err is ErrOutOfSync, and reply
+// carries the server's text, resetting both sessions.
+func (s *Session) Handle(m Message) (reply *Message, err error) {
+	if m.Seq <= s.seq {
+		return nil, nil
+	}
+	s.seq = m.Seq
+	if !m.Reset {
+		err = s.receive(&m)
+	}
+	if m.Reset || err != nil {
+		text := s.doc.Text()
+		s.reset(text)
+		return &Message{ReplyTo: m.Seq, Reset: true, Text: text}, err
+	}
+	r := s.flush()
+	r.ReplyTo = m.Seq
+	return &r, nil
+}
+
+// Push the local changes onto the edit stack, and return
+// a message containing the stack.
+func (s *Session) flush() Message {
+	if text := s.doc.Text(); text != s.shadow {
+		diffs := dmp.DiffMain(s.shadow, text, false, 0)
+		diffs.CleanupEfficiency(0)
+		s.stack = append(s.stack, Edit{Version: s.localVersion, Delta: diffs.ToDelta()})
+		s.shadow = text
+		s.localVersion++
+	}
+	return Message{
+		Ack:   s.remoteVersion,
+		Edits: append([]Edit(nil), s.stack...),
+	}
+}
+
+func (s *Session) receive(m *Message) error {
+	if m.Ack > s.localVersion {
+		return ErrOutOfSync
+	}
+
+	// Drop the edits the peer has applied.
+	n := 0
+	for n < len(s.stack) && s.stack[n].Version < m.Ack {
+		n++
+	}
+	s.stack = s.stack[n:]
+
+	if m.Ack != s.localVersion {
+		if m.Ack != s.backupVersion {
+			return ErrOutOfSync
+		}
+		// The peer has not received the edits sent since the
+		// backup has been taken. Restore it; the changes will be
+		// sent again by the next flush.
+		s.shadow = s.backup
+		s.localVersion = s.backupVersion
+		s.stack = nil
+	}
+
+	// Combine the new edits, so that the Document is
+	// changed only if all of them fit.
+	shadow, version := s.shadow, s.remoteVersion
+	var diffs dmp.Diffs
+	for _, e := range m.Edits {
+		switch {
+		case e.Version < version:
+			// already applied
+			continue
+		case e.Version > version:
+			return ErrOutOfSync
+		}
+		d, err := dmp.FromDelta(shadow, e.Delta)
+		if err != nil {
+			return ErrOutOfSync
+		}
+		if diffs == nil {
+			diffs = d
+		} else if diffs, err = dmp.Compose(diffs, d); err != nil {
+			return ErrOutOfSync
+		}
+		shadow = d.Text2()
+		version++
+	}
+	if diffs != nil {
+		if err := s.doc.merge(s.shadow, diffs); err != nil {
+			return ErrOutOfSync
+		}
+	}
+	s.shadow, s.remoteVersion = shadow, version
+	s.backup = s.shadow
+	s.backupVersion = s.localVersion
+	return nil
+}
@@ -0,0 +1,259 @@
+// Diff Match and Patch – differential synchronization tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package diffsync
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/knieriem/dmp"
+)
+
+func TestSync(t *testing.T) {
+	server := NewDocument("The quick brown fox jumps over the lazy dog.")
+	client := NewDocument(server.Text())
+	cs, ss := NewSession(client), NewSession(server)
+
+	client.SetText("The quick brown fox jumps over the lazy cat.")
+	server.SetText("The slow brown fox jumps over the lazy dog.")
+	exchange(t, cs, ss)
+	want := "The slow brown fox jumps over the lazy cat."
+	if text := server.Text(); text != want {
+		t.Errorf("server: %q, want %q", text, want)
+	}
+	if text := client.Text(); text != want {
+		t.Errorf("client: %q, want %q", text, want)
+	}
+}
+
+func TestSyncLostReply(t *testing.T) {
+	server := NewDocument("abc")
+	client := NewDocument(server.Text())
+	cs, ss := NewSession(client), NewSession(server)
+
+	client.SetText("abcd")
+	server.SetText("xabc")
+	if _, err := ss.Handle(cs.Flush()); err != nil {
+		t.Fatal(err)
+	}
+	// The reply is lost; the client retries.
+	client.SetText("abcde")
+	server.SetText("xyabcd")
+	exchange(t, cs, ss)
+	for _, doc := range []*Document{server, client} {
+		if text := doc.Text(); text != "xyabcde" {
+			t.Errorf("text: %q, want %q", text, "xyabcde")
+		}
+	}
+	if len(cs.stack) != 0 || len(ss.stack) != 1 {
+		t.Errorf("stack sizes: %d, %d", len(cs.stack), len(ss.stack))
+	}
+}
+
+func TestSyncOutOfSync(t *testing.T) {
+	ss := NewSession(NewDocument("abc"))
+	_, err := ss.Handle(Message{Seq: 1, Edits: []Edit{{Version: 0, Delta: "=4"}}})
+	if err != ErrOutOfSync {
+		t.Errorf("mismatching delta: %v", err)
+	}
+	ss = NewSession(NewDocument("abc"))
+	_, err = ss.Handle(Message{Seq: 1, Edits: []Edit{{Version: 1, Delta: "=3"}}})
+	if err != ErrOutOfSync {
+		t.Errorf("missing edit: %v", err)
+	}
+
+	// A message is merged only if all of its edits fit.
+	doc := NewDocument("abc")
+	ss = NewSession(doc)
+	_, err = ss.Handle(Message{Seq: 1, Edits: []Edit{{Version: 0, Delta: "=3\t+d"}, {Version: 1, Delta: "=9"}}})
+	if err != ErrOutOfSync || doc.Text() != "abc" {
+		t.Errorf("partly mismatching message: %q, %v", doc.Text(), err)
+	}
+
+	if err := NewDocument("abd").merge("abc", dmp.Diffs{{Op: dmp.Equal, Text: "x"}}); err == nil {
+		t.Error("mismatching merge not detected")
+	}
+}
+
+func TestSyncReset(t *testing.T) {
+	server := NewDocument("abc")
+	client := NewDocument("wxyz")
+	cs, ss := NewSession(client), NewSession(server)
+
+	// The server rejects the client's edit, and sends its text.
+	client.SetText("wxyz!")
+	reply, err := ss.Handle(cs.Flush())
+	if err != ErrOutOfSync || reply == nil || !reply.Reset || reply.Text != "abc" {
+		t.Fatalf("server reset: %+v, %v", reply, err)
+	}
+	if err = cs.Receive(*reply); err != nil || client.Text() != "abc" {
+		t.Fatalf("client reset: %q, %v", client.Text(), err)
+	}
+	client.SetText("abcd")
+	exchange(t, cs, ss)
+	if text := server.Text(); text != "abcd" {
+		t.Errorf("after server reset: %q", text)
+	}
+
+	// The client rejects the server's answer, and requests its text.
+	server.SetText("abcde")
+	if err = cs.Receive(Message{ReplyTo: cs.Flush().Seq, Edits: []Edit{{Version: 1, Delta: "=9"}}}); err != ErrOutOfSync {
+		t.Fatalf("mismatching answer: %v", err)
+	}
+	m := cs.Flush()
+	if !m.Reset {
+		t.Fatalf("reset not requested: %+v", m)
+	}
+	reply, err = ss.Handle(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cs.Receive(*reply); err != nil || client.Text() != "abcde" {
+		t.Errorf("after client reset: %q, %v", client.Text(), err)
+	}
+	client.SetText("abcdef")
+	exchange(t, cs, ss)
+	if text := server.Text(); text != "abcdef" {
+		t.Errorf("after client reset: %q", text)
+	}
+}
+
+func exchange(t *testing.T, cs, ss *Session) {
+	reply, err := ss.Handle(cs.Flush())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cs.Receive(*reply); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A link transports messages in one direction. It may lose,
+// duplicate, and reorder them.
+type link struct {
+	rnd   *rand.Rand
+	loss  float64
+	queue [][]byte
+}
+
+func (l *link) send(t *testing.T, m Message) {
+	if l.rnd.Float64() < l.loss {
+		return
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.queue = append(l.queue, b)
+}
+
+func (l *link) receive(t *testing.T) (m Message, ok bool) {
+	if len(l.queue) == 0 {
+		return m, false
+	}
+	i := l.rnd.Intn(len(l.queue))
+	if err := json.Unmarshal(l.queue[i], &m); err != nil {
+		t.Fatal(err)
+	}
+	if l.rnd.Float64() >= l.loss {
+		// not duplicated
+		l.queue = append(l.queue[:i], l.queue[i+1:]...)
+	}
+	return m, true
+}
+
+type peer struct {
+	doc      *Document
+	cs, ss   *Session
+	up, down *link
+}
+
+var words = strings.Fields("alpha beta gamma delta epsilon zeta eta theta")
+
+func randomEdit(rnd *rand.Rand, doc *Document) {
+	text := doc.Text()
+	i := rnd.Intn(len(text) + 1)
+	if rnd.Intn(2) == 0 || i == len(text) {
+		doc.SetText(text[:i] + " " + words[rnd.Intn(len(words))] + text[i:])
+		return
+	}
+	j := min(i+1+rnd.Intn(5), len(text))
+	doc.SetText(text[:i] + text[j:])
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func TestSyncLossyTransport(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	server := NewDocument("The quick brown fox jumps over the lazy dog.")
+	peers := make([]*peer, 3)
+	for i := range peers {
+		doc := NewDocument(server.Text())
+		peers[i] = &peer{
+			doc:  doc,
+			cs:   NewSession(doc),
+			ss:   NewSession(server),
+			up:   &link{rnd: rnd, loss: 0.2},
+			down: &link{rnd: rnd, loss: 0.2},
+		}
+	}
+
+	for round := 0; round < 500; round++ {
+		if k := rnd.Intn(len(peers) + 1); k == len(peers) {
+			randomEdit(rnd, server)
+		} else {
+			randomEdit(rnd, peers[k].doc)
+		}
+		for _, p := range peers {
+			if rnd.Intn(3) == 0 {
+				p.up.send(t, p.cs.Flush())
+			}
+			for rnd.Intn(3) != 0 {
+				m, ok := p.up.receive(t)
+				if !ok {
+					break
+				}
+				reply, err := p.ss.Handle(m)
+				if err != nil {
+					t.Fatalf("round %d: server: %v", round, err)
+				}
+				if reply != nil {
+					p.down.send(t, *reply)
+				}
+			}
+			for rnd.Intn(3) != 0 {
+				m, ok := p.down.receive(t)
+				if !ok {
+					break
+				}
+				if err := p.cs.Receive(m); err != nil {
+					t.Fatalf("round %d: client: %v", round, err)
+				}
+			}
+		}
+	}
+
+	// Settle using a reliable transport.
+	for i := 0; i < 2; i++ {
+		for _, p := range peers {
+			exchange(t, p.cs, p.ss)
+		}
+	}
+	want := server.Text()
+	for i, p := range peers {
+		if text := p.doc.Text(); text != want {
+			t.Errorf("client %d: %q, want %q", i, text, want)
+		}
+	}
+}
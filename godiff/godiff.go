@@ -0,0 +1,217 @@
+// Diff Match and Patch – token-level diffs of Go source code
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package godiff compares Go source files token by token, using
+go/scanner, so that changes of the code can be told apart from changes
+of its formatting, like those made by gofmt.
+
+Differences in the white space between tokens, between explicit and
+automatically inserted semicolons, and in the letter case of number
+literal prefixes and exponents, are formatting differences; all other
+differences, including changes of comments, are changes of the code.
+*/
+package godiff
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/knieriem/dmp"
+)
+
+// An Edit replaces the bytes src1[Start1:End1] by src2[Start2:End2].
+type Edit struct {
+	Start1, End1 int
+	Start2, End2 int
+	Formatting   bool // only the formatting differs
+}
+
+// Compare two Go source files, and return the list of edits
+// transforming src1 into src2, sorted by position. Adjacent edits
+// of the same kind are merged. If ignoreFormatting is set, edits
+// changing only the formatting are left out. If a file can not be
+// tokenized, a scanner.ErrorList is returned.
+func Diff(src1, src2 []byte, ignoreFormatting bool) ([]Edit, error) {
+	t1, err := tokenize(src1)
+	if err != nil {
+		return nil, err
+	}
+	t2, err := tokenize(src2)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []Edit
+	add := func(e Edit) {
+		if e.Formatting && ignoreFormatting {
+			return
+		}
+		if n := len(edits); n != 0 {
+			// Merge with the previous edit, if adjacent.
+			if p := &edits[n-1]; p.Formatting == e.Formatting && p.End1 == e.Start1 && p.End2 == e.Start2 {
+				p.End1, p.End2 = e.End1, e.End2
+				return
+			}
+		}
+		edits = append(edits, e)
+	}
+
+	var (
+		i1, i2 int // indices of the current tokens
+		e1, e2 int // ends of the last equal tokens
+
+		// ranges of the tokens changed since
+		changed1, changed2 bool
+		c1, c2             [2]int
+	)
+	gap := func(start1, end1, start2, end2 int) {
+		if !bytes.Equal(src1[start1:end1], src2[start2:end2]) {
+			add(Edit{Start1: start1, End1: end1, Start2: start2, End2: end2, Formatting: true})
+		}
+	}
+	// Compare the regions up to the equal tokens starting at s1 and s2.
+	// Changed tokens are reported as an edit, separately from the white
+	// space around them. If only one side has changed tokens, the
+	// position of the edit on the other side is the end of the last
+	// equal token.
+	sync := func(s1, s2 int) {
+		if !changed1 && !changed2 {
+			gap(e1, s1, e2, s2)
+			return
+		}
+		if !changed1 {
+			c1 = [2]int{e1, e1}
+		}
+		if !changed2 {
+			c2 = [2]int{e2, e2}
+		}
+		gap(e1, c1[0], e2, c2[0])
+		add(Edit{Start1: c1[0], End1: c1[1], Start2: c2[0], End2: c2[1]})
+		gap(c1[1], s1, c2[1], s2)
+		changed1, changed2 = false, false
+	}
+	keys := func(tokens []tok) []string {
+		k := make([]string, len(tokens))
+		for i, t := range tokens {
+			k[i] = t.key
+		}
+		return k
+	}
+	for _, td := range dmp.DiffTokens(keys(t1), keys(t2), dmp.NoTimeout) {
+		n := len(td.Tokens)
+		switch td.Op {
+		case dmp.Delete:
+			if !changed1 {
+				c1[0] = t1[i1].start
+				changed1 = true
+			}
+			i1 += n
+			c1[1] = t1[i1-1].end
+		case dmp.Insert:
+			if !changed2 {
+				c2[0] = t2[i2].start
+				changed2 = true
+			}
+			i2 += n
+			c2[1] = t2[i2-1].end
+		case dmp.Equal:
+			for ; n > 0; n-- {
+				a, b := &t1[i1], &t2[i2]
+				sync(a.start, b.start)
+				e1, e2 = a.end, b.end
+				gap(a.start, a.end, b.start, b.end)
+				i1++
+				i2++
+			}
+		}
+	}
+	sync(len(src1), len(src2))
+	return edits, nil
+}
+
+// A tok is a token located at src[start:end].
+type tok struct {
+	key        string // identifies the token, regardless of its formatting
+	start, end int
+}
+
+func tokenize(src []byte) ([]tok, error) {
+	var (
+		s      scanner.Scanner
+		errs   scanner.ErrorList
+		tokens []tok
+	)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	s.Init(file, src, errs.Add, scanner.ScanComments)
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		start := file.Offset(pos)
+		end := start + len(t.String())
+		key := t.String()
+		switch {
+		case t == token.SEMICOLON:
+			if lit != ";" {
+				// inserted automatically
+				end = start
+			}
+		case t == token.COMMENT:
+			end = commentEnd(src, start)
+			key += " " + lit
+		case t == token.STRING && src[start] == '`':
+			end = start + 1 + bytes.IndexByte(src[start+1:], '`') + 1
+			key += " " + lit
+		case t == token.INT, t == token.FLOAT, t == token.IMAG:
+			end = start + len(lit)
+			key += " " + normalizeNumber(lit)
+		case t.IsLiteral():
+			end = start + len(lit)
+			key += " " + lit
+		}
+		tokens = append(tokens, tok{key, start, end})
+	}
+	errs.Sort()
+	return tokens, errs.Err()
+}
+
+// Return a number literal with its prefix and exponent
+// in lower case, like gofmt writes it. The case of hex
+// digits is kept.
+func normalizeNumber(lit string) string {
+	exp := "eE"
+	if len(lit) > 1 && lit[0] == '0' && strings.IndexByte("xXbBoO", lit[1]) != -1 {
+		if lit[1] == 'x' || lit[1] == 'X' {
+			exp = "pP"
+		}
+		lit = "0" + strings.ToLower(lit[1:2]) + lit[2:]
+	}
+	if i := strings.IndexAny(lit, exp); i != -1 {
+		lit = lit[:i] + strings.ToLower(lit[i:i+1]) + lit[i+1:]
+	}
+	return lit
+}
+
+// Return the end of the comment starting at src[start:]. Its literal
+// can not be used, as carriage returns are removed from it.
+func commentEnd(src []byte, start int) int {
+	if src[start+1] == '/' {
+		if i := bytes.IndexByte(src[start:], '\n'); i != -1 {
+			end := start + i
+			if src[end-1] == '\r' {
+				end--
+			}
+			return end
+		}
+		return len(src)
+	}
+	return start + bytes.Index(src[start:], []byte("*/")) + 2
+}
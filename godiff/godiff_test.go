@@ -0,0 +1,122 @@
+// Diff Match and Patch – Go source diff tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package godiff
+
+import (
+	"fmt"
+	"go/scanner"
+	"testing"
+)
+
+const src1 = `package p
+
+func f(a int)int{
+	x:=0X1f;y:=1E3 // comment
+	return a+x+int(y)
+}
+`
+
+const src2 = `package p
+
+func f(a int) int {
+	x := 0x1f
+	y := 1e3 // comment
+	return a + x*2 + int(y)
+}
+`
+
+func TestDiff(t *testing.T) {
+	edits, err := Diff([]byte(src1), []byte(src2), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, e := range edits {
+		s := fmt.Sprintf("%q→%q", src1[e.Start1:e.End1], src2[e.Start2:e.End2])
+		if e.Formatting {
+			s = "~" + s
+		}
+		have = append(have, s)
+	}
+	want := []string{
+		`~""→" "`, `~""→" "`,
+		`~""→" "`, `~"0X1f;"→" 0x1f\n\t"`, `~""→" "`, `~"1E3"→" 1e3"`,
+		`~""→" "`, `~""→" "`, `""→"*2"`, `~""→" "`, `~""→" "`,
+	}
+	if fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Errorf("Edits:\n have %q\n want %q", have, want)
+	}
+
+	edits, err = Diff([]byte(src1), []byte(src2), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 1 || src2[edits[0].Start2:edits[0].End2] != "*2" || edits[0].Start1 != edits[0].End1 {
+		t.Errorf("IgnoreFormatting: %v", edits)
+	}
+}
+
+func TestDiffNumbers(t *testing.T) {
+	for _, x := range []struct {
+		a, b       string
+		formatting bool
+	}{
+		{"0X1f", "0x1f", true},
+		{"0B101", "0b101", true},
+		{"0O17", "0o17", true},
+		{"1E3", "1e3", true},
+		{"0x1P-2", "0x1p-2", true},
+		{"0xABC", "0xabc", false},
+		{"0x1E", "0x1e", false},
+	} {
+		edits, err := Diff([]byte("package p\nvar x = "+x.a+"\n"), []byte("package p\nvar x = "+x.b+"\n"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(edits) != 1 || edits[0].Formatting != x.formatting {
+			t.Errorf("%s, %s: %v", x.a, x.b, edits)
+		}
+	}
+}
+
+func TestDiffComments(t *testing.T) {
+	a := "package p\r\n\r\n// old\r\nvar x = `a\r\nb`\r\n"
+	b := "package p\n\n// new\nvar x = `a\nb`\n"
+	edits, err := Diff([]byte(a), []byte(b), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 1 {
+		t.Fatalf("Edits: %v", edits)
+	}
+	if e := edits[0]; a[e.Start1:e.End1] != "// old" || b[e.Start2:e.End2] != "// new" {
+		t.Errorf("Comment: %v", e)
+	}
+
+	// Carriage returns are discarded from raw strings, so they
+	// are a formatting difference there.
+	edits, err = Diff([]byte(a), []byte(b), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for _, e := range edits {
+		if e.Formatting {
+			n++
+		}
+	}
+	if n != len(edits)-1 || len(edits) < 2 {
+		t.Errorf("Formatting edits: %v", edits)
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	_, err := Diff([]byte("package p\nvar s = \"x\n"), []byte("package p\n"), false)
+	if _, ok := err.(scanner.ErrorList); !ok {
+		t.Errorf("Error: %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+// Diff Match and Patch – HTML-aware diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package htmldiff compares HTML documents, or fragments, and renders
+the changes as HTML, marking deleted content using <del>, and inserted
+content using <ins> elements.
+
+The markup is split into tokens – tags, comments, and the words and
+white space of text – which are compared using dmp.DiffTokens, so that
+tags never get split. The result has the structure of the new document:
+<del> and <ins> only enclose text, and complete elements. Deleted tags
+not forming complete elements are left out, inserted ones are kept
+without being marked. Elements that may only appear within specific
+parents, like list items and table cells, are not enclosed themselves;
+their content is marked instead. Changes of attributes are not shown.
+Deleted scripts and style sheets are left out, and of changed content
+of raw text elements, like scripts, only the new one is kept.
+*/
+package htmldiff
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/knieriem/dmp"
+)
+
+// Compare two HTML texts, and return the second one, with the
+// changes marked up.
+func Diff(html1, html2 string) string {
+	var b strings.Builder
+	raw := false // does the output end with the start tag of a raw text element?
+	for _, td := range dmp.DiffTokens(tokenize(html1), tokenize(html2), 0) {
+		tokens := td.Tokens
+		if raw && len(tokens) != 0 && !isTag(tokens[0]) {
+			// The content of a raw text element can not
+			// be marked up; only the new one is kept.
+			if td.Op != dmp.Delete {
+				b.WriteString(tokens[0])
+			}
+			tokens = tokens[1:]
+			if td.Op == dmp.Delete && len(tokens) == 0 {
+				continue
+			}
+		}
+		raw = false
+		switch td.Op {
+		case dmp.Equal:
+			for _, t := range tokens {
+				if isTag(t) || raw {
+					b.WriteString(t)
+				} else {
+					b.WriteString(escapeText(t))
+				}
+				raw = isRawStart(t)
+			}
+		case dmp.Delete:
+			mark(&b, "del", tokens)
+		case dmp.Insert:
+			mark(&b, "ins", tokens)
+		}
+	}
+	return b.String()
+}
+
+// Write a run of deleted or inserted tokens, enclosing text and
+// complete elements in elements named tag. Deleted scripts and style
+// sheets are left out, so that they do not take effect.
+func mark(b *strings.Builder, tag string, tokens []string) {
+	open := false // is a marking element open?
+	setOpen := func(o bool) {
+		if o != open {
+			if o {
+				b.WriteString("<" + tag + ">")
+			} else {
+				b.WriteString("</" + tag + ">")
+			}
+			open = o
+		}
+	}
+	end := matchElements(tokens)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case !isTag(t) && i > 0 && isRawStart(tokens[i-1]):
+			// the content of an incomplete raw text element
+			if tag == "ins" {
+				b.WriteString(t)
+			}
+		case !isTag(t):
+			setOpen(true)
+			b.WriteString(escapeText(t))
+		case end[i] == -1:
+			// an incomplete element
+			setOpen(false)
+			if tag == "ins" {
+				b.WriteString(t)
+			}
+		case needsParent[tagName(t)]:
+			setOpen(false)
+			b.WriteString(t)
+			mark(b, tag, tokens[i+1:end[i]])
+			b.WriteString(tokens[end[i]])
+			i = end[i]
+		case tag == "del" && activeElements[tagName(t)]:
+			i = end[i]
+		default:
+			setOpen(true)
+			for k := i; k <= end[i]; k++ {
+				t := tokens[k]
+				if tag == "del" && isTag(t) && activeElements[tagName(t)] && end[k] != -1 {
+					k = end[k]
+					continue
+				}
+				b.WriteString(t)
+			}
+			i = end[i]
+		}
+	}
+	setOpen(false)
+}
+
+// Report whether t is the start tag of a raw text element.
+func isRawStart(t string) bool {
+	return isTag(t) && !strings.HasPrefix(t, "</") && rawTextElements[tagName(t)]
+}
+
+// Escape the characters of a text token that would be taken
+// as markup. Character references are kept.
+func escapeText(t string) string {
+	return textEscaper.Replace(t)
+}
+
+var textEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;")
+
+// For each start tag of tokens, find the index of the matching end
+// tag. The result contains -1 for tags, or comments, not being part of
+// a complete element. Void elements, and self-closing tags, are
+// complete elements by themselves.
+func matchElements(tokens []string) []int {
+	end := make([]int, len(tokens))
+	matched := make([]bool, len(tokens)) // end tags having a start tag
+	var stack []int                      // indices of start tags
+	for i, t := range tokens {
+		end[i] = -1
+		if !isTag(t) || strings.HasPrefix(t, "<!") || strings.HasPrefix(t, "<?") {
+			continue
+		}
+		name := tagName(t)
+		switch {
+		case strings.HasPrefix(t, "</"):
+			// Find the start tag, dropping unclosed ones in between.
+			for k := len(stack) - 1; k >= 0; k-- {
+				if tagName(tokens[stack[k]]) == name {
+					end[stack[k]] = i
+					matched[i] = true
+					stack = stack[:k]
+					break
+				}
+			}
+		case voidElements[name] || strings.HasSuffix(t, "/>"):
+			end[i] = i
+		case rawTextElements[name]:
+			// The content is a single token, if any.
+			for k := i + 1; k <= i+2 && k < len(tokens); k++ {
+				if strings.HasPrefix(tokens[k], "</") && tagName(tokens[k]) == name {
+					end[i] = k
+					matched[k] = true
+					break
+				}
+			}
+		default:
+			stack = append(stack, i)
+		}
+	}
+	// Elements containing incomplete ones are not complete.
+	for i := range tokens {
+		for k := i + 1; k < end[i]; k++ {
+			if t := tokens[k]; isTag(t) && t[1] != '!' && t[1] != '?' {
+				if strings.HasPrefix(t, "</") && !matched[k] || !strings.HasPrefix(t, "</") && end[k] == -1 {
+					end[i] = -1
+					break
+				}
+			}
+		}
+	}
+	return end
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// Elements taking effect on the rendered document.
+var activeElements = map[string]bool{
+	"script": true, "style": true,
+}
+
+// Elements that may only appear within specific parents.
+var needsParent = map[string]bool{
+	"li": true, "dt": true, "dd": true,
+	"tr": true, "td": true, "th": true,
+	"thead": true, "tbody": true, "tfoot": true, "caption": true,
+	"colgroup": true, "option": true, "optgroup": true,
+}
+
+func isTag(t string) bool {
+	return len(t) > 1 && t[0] == '<' && t[len(t)-1] == '>'
+}
+
+// Return the lower-case name of a tag.
+func tagName(t string) string {
+	t = strings.TrimPrefix(t[1:], "/")
+	i := strings.IndexFunc(t, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '/' || r == '>'
+	})
+	if i == -1 {
+		return ""
+	}
+	return strings.ToLower(t[:i])
+}
+
+// Split HTML into tags, comments, the content of raw text elements,
+// words, runs of white space, and single other characters.
+// Character references are kept within words.
+func tokenize(html string) (tokens []string) {
+	for len(html) > 0 {
+		n := tokenLen(html)
+		t := html[:n]
+		tokens = append(tokens, t)
+		html = html[n:]
+		if isTag(t) && !strings.HasPrefix(t, "</") && rawTextElements[tagName(t)] {
+			// The content of the element is a single token.
+			i := strings.Index(strings.ToLower(html), "</"+tagName(t))
+			if i == -1 {
+				i = len(html)
+			}
+			if i > 0 {
+				tokens = append(tokens, html[:i])
+				html = html[i:]
+			}
+		}
+	}
+	return
+}
+
+// Return the length of the token at the start of html.
+func tokenLen(html string) int {
+	if strings.HasPrefix(html, "<!--") {
+		if i := strings.Index(html[4:], "-->"); i != -1 {
+			return 4 + i + 3
+		}
+		return len(html)
+	}
+	if html[0] == '<' && len(html) > 1 {
+		if c := html[1]; c == '/' || c == '!' || c == '?' || c < utf8.RuneSelf && unicode.IsLetter(rune(c)) {
+			return tagLen(html)
+		}
+	}
+	r, size := utf8.DecodeRuneInString(html)
+	switch {
+	case unicode.IsSpace(r):
+		return len(html) - len(strings.TrimLeftFunc(html, unicode.IsSpace))
+	case isWordChar(r) || r == '&':
+		n := 0
+		for n < len(html) {
+			r, size := utf8.DecodeRuneInString(html[n:])
+			switch {
+			case isWordChar(r):
+				n += size
+			case r == '&':
+				m := entityLen(html[n:])
+				if m == 0 {
+					if n == 0 {
+						return 1
+					}
+					return n
+				}
+				n += m
+			default:
+				return n
+			}
+		}
+		return n
+	}
+	return size
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// Return the length of the character reference at the start of s,
+// or 0, if there is none.
+func entityLen(s string) int {
+	i := 1
+	if i < len(s) && s[i] == '#' {
+		i++
+	}
+	for i < len(s) && (s[i] < utf8.RuneSelf && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])))) {
+		i++
+	}
+	if i < len(s) && s[i] == ';' && i > 1 {
+		return i + 1
+	}
+	return 0
+}
+
+// Return the length of the tag at the start of html, honouring
+// quoted attribute values.
+func tagLen(html string) int {
+	var quote byte
+	for i := 1; i < len(html); i++ {
+		switch c := html[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(html)
+}
@@ -0,0 +1,102 @@
+// Diff Match and Patch – HTML-aware diff tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package htmldiff
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, x := range []struct {
+		html   string
+		tokens []string
+	}{
+		{`<p class="a>b">Caf&eacute; au lait!</p>`, []string{`<p class="a>b">`, `Caf&eacute;`, " ", "au", " ", "lait", "!", `</p>`}},
+		{"a < b &amp c<!-- <x> -->", []string{"a", " ", "<", " ", "b", " ", "&", "amp", " ", "c", "<!-- <x> -->"}},
+		{`<script>if (a<b) x()</SCRIPT>`, []string{`<script>`, `if (a<b) x()`, `</SCRIPT>`}},
+		{`<br/>x<style></style>`, []string{`<br/>`, "x", `<style>`, `</style>`}},
+	} {
+		if have := tokenize(x.html); fmt.Sprintf("%q", have) != fmt.Sprintf("%q", x.tokens) {
+			t.Errorf("%s:\n have %q\n want %q", x.html, have, x.tokens)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, x := range []struct {
+		descr string
+		html1 string
+		html2 string
+		want  string
+	}{
+		{"Text",
+			`<p>The quick brown fox</p>`,
+			`<p>The slow brown fox</p>`,
+			`<p>The <del>quick</del><ins>slow</ins> brown fox</p>`},
+		{"Element inserted",
+			`<p>a</p>`,
+			`<p>a</p><p>b <b>c</b></p>`,
+			`<p>a</p><ins><p>b <b>c</b></p></ins>`},
+		{"Formatting added",
+			`<p>a b</p>`,
+			`<p>a <b>b</b></p>`,
+			`<p>a <b>b</b></p>`},
+		{"Formatting removed",
+			`<p>a <i>b</i> c</p>`,
+			`<p>a b c</p>`,
+			`<p>a b c</p>`},
+		{"Paragraphs joined",
+			`<p>a</p><p>b</p>`,
+			`<p>a b</p>`,
+			`<p>a<ins> </ins>b</p>`},
+		{"Paragraph split",
+			`<p>a b</p>`,
+			`<p>a</p><p>b</p>`,
+			`<p>a<del> </del></p><p>b</p>`},
+		{"Attribute changed",
+			`<a href="x">link</a>`,
+			`<a href="y">link</a>`,
+			`<a href="y">link</a>`},
+		{"List item deleted",
+			`<ul><li>a</li><li>b <em>c</em></li></ul>`,
+			`<ul><li>a</li></ul>`,
+			`<ul><li>a</li><li><del>b <em>c</em></del></li></ul>`},
+		{"Void element",
+			`a<br>b`,
+			`a<img src="x.png">b`,
+			`a<del><br></del><ins><img src="x.png"></ins>b`},
+		{"Incomplete element deleted",
+			`<div><p>a</p></div>`,
+			`<p>a</p>`,
+			`<p>a</p>`},
+		{"Script deleted",
+			`<p>a</p><script>alert(1)</script><div>b<style>p{}</style></div>`,
+			`<p>a</p>`,
+			`<p>a</p><del><div>b</div></del>`},
+		{"Script inserted",
+			`<p>a</p>`,
+			`<p>a</p><script>x()</script>`,
+			`<p>a</p><ins><script>x()</script></ins>`},
+		{"Script changed",
+			`<script>if (a<b) x()</script>`,
+			`<script>if (a<b) y()</script>`,
+			`<script>if (a<b) y()</script>`},
+		{"Unterminated tag",
+			`<p>a</p>`,
+			`<p>a</p><b x="y`,
+			`<p>a</p><ins>&lt;b x="y</ins>`},
+		{"Unterminated tag kept",
+			`a <b c`,
+			`a <b c`,
+			`a &lt;b c`},
+	} {
+		if have := Diff(x.html1, x.html2); have != x.want {
+			t.Errorf("%s:\n have %s\n want %s", x.descr, have, x.want)
+		}
+	}
+}
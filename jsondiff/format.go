@@ -0,0 +1,102 @@
+// Diff Match and Patch – human-readable JSON diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/knieriem/dmp"
+)
+
+// Format the Diff as a pretty-printed JSON document, in which each line
+// is prefixed by a marker: a space for unchanged lines, "-" for lines
+// of the old document, "+" for lines of the new one, and "~" for strings
+// that have been changed partly. The changes within these are enclosed
+// in [-…-] and {+…+}, as by wdiff.
+func (d *Diff) String() string {
+	var b strings.Builder
+	d.format(&b, "", false, "")
+	return b.String()
+}
+
+const indentStep = "  "
+
+func (d *Diff) format(b *strings.Builder, indent string, member bool, comma string) {
+	key := ""
+	if member {
+		key = encode(d.Key) + ": "
+	}
+	switch d.Op {
+	case dmp.Equal:
+		writeValue(b, ' ', indent, key, d.Old, comma)
+	case dmp.Delete:
+		writeValue(b, '-', indent, key, d.Old, comma)
+	case dmp.Insert:
+		writeValue(b, '+', indent, key, d.New, comma)
+	case Replace:
+		if d.Text == nil {
+			writeValue(b, '-', indent, key, d.Old, comma)
+			writeValue(b, '+', indent, key, d.New, comma)
+			break
+		}
+		b.WriteString("~ " + indent + key + `"`)
+		for _, t := range d.Text {
+			text := strings.TrimSuffix(strings.TrimPrefix(encode(t.Text), `"`), `"`)
+			switch t.Op {
+			case dmp.Delete:
+				b.WriteString("[-" + text + "-]")
+			case dmp.Insert:
+				b.WriteString("{+" + text + "+}")
+			default:
+				b.WriteString(text)
+			}
+		}
+		b.WriteString(`"` + comma + "\n")
+	case Modify:
+		open, close := "[", "]"
+		_, isObject := d.Old.(map[string]interface{})
+		if isObject {
+			open, close = "{", "}"
+		}
+		b.WriteString("  " + indent + key + open + "\n")
+		for i, e := range d.Elems {
+			c := ","
+			if i == len(d.Elems)-1 {
+				c = ""
+			}
+			e.format(b, indent+indentStep, isObject, c)
+		}
+		b.WriteString("  " + indent + close + comma + "\n")
+	}
+}
+
+// Write a pretty-printed value, prefixing each line with mark.
+// As for encode, the value must be encodable.
+func writeValue(b *strings.Builder, mark byte, indent, key string, v interface{}, comma string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(indent, indentStep)
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		b.WriteByte(mark)
+		b.WriteByte(' ')
+		if i == 0 {
+			b.WriteString(indent + key)
+		}
+		b.WriteString(line)
+		if i == len(lines)-1 {
+			b.WriteString(comma)
+		}
+		b.WriteByte('\n')
+	}
+}
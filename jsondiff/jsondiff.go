@@ -0,0 +1,271 @@
+// Diff Match and Patch – structured diffs of JSON documents
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package jsondiff compares JSON documents structurally, rather than
+as text: members of objects are matched by name, regardless of their
+order, and the elements of arrays are aligned using dmp.DiffTokens.
+Strings replaced by strings are diffed using dmp.DiffMain.
+
+Values are expected in the form produced by encoding/json when
+decoding into an interface{}: map[string]interface{}, []interface{},
+string, float64 or json.Number, bool, and nil.
+
+The result can be converted into a JSON Patch (RFC 6902), and be
+displayed in a human-readable form.
+*/
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/knieriem/dmp"
+)
+
+// Operations, in addition to dmp.Equal, dmp.Insert, and dmp.Delete.
+const (
+	Replace = '~' // a value has been replaced
+	Modify  = '*' // an object or array has been changed inside
+)
+
+// ErrTrailingData reports that a document contains more than one value.
+var ErrTrailingData = errors.New("jsondiff: data after top-level value")
+
+// A Diff describes how a JSON value has changed.
+type Diff struct {
+	Op       int
+	Key      string      // name of the member, if the value is part of an object
+	Old, New interface{} // the value of both documents, as far as present
+
+	// If Op is Replace, and a string has been replaced by a string,
+	// Text contains the changes of the text.
+	Text dmp.Diffs
+
+	// If Op is Modify, Elems contains the members of an object,
+	// sorted by name, or the elements of an array.
+	Elems []*Diff
+}
+
+// Compare two decoded JSON values. An error is returned
+// if a value can not be encoded as JSON, like NaN.
+func Compare(a, b interface{}) (*Diff, error) {
+	for _, v := range []interface{}{a, b} {
+		if _, err := json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return compare("", a, b), nil
+}
+
+// Compare two JSON documents. Numbers are compared as written,
+// so 1 and 1.0 differ. Each document must consist of a single value,
+// otherwise ErrTrailingData is returned.
+func CompareJSON(a, b []byte) (*Diff, error) {
+	va, err := decode(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(va, vb)
+}
+
+func decode(data []byte) (v interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.Decode(new(json.RawMessage)) != io.EOF {
+		return nil, ErrTrailingData
+	}
+	return v, nil
+}
+
+func compare(key string, a, b interface{}) *Diff {
+	d := &Diff{Op: Replace, Key: key, Old: a, New: b}
+	if encode(a) == encode(b) {
+		d.Op = dmp.Equal
+		return d
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			d.Op = Modify
+			d.Elems = compareObjects(a, b)
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			d.Op = Modify
+			d.Elems = compareArrays(a, b)
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			d.Text = dmp.DiffMain(a, b, false, 0)
+			d.Text.CleanupSemantic()
+		}
+	}
+	return d
+}
+
+func compareObjects(a, b map[string]interface{}) []*Diff {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	elems := make([]*Diff, len(keys))
+	for i, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			elems[i] = &Diff{Op: dmp.Delete, Key: k, Old: va}
+		case !inA:
+			elems[i] = &Diff{Op: dmp.Insert, Key: k, New: vb}
+		default:
+			elems[i] = compare(k, va, vb)
+		}
+	}
+	return elems
+}
+
+// Align the elements of two arrays. Elements replaced by
+// others are paired in order, and compared in turn.
+func compareArrays(a, b []interface{}) (elems []*Diff) {
+	tokens := func(values []interface{}) []string {
+		t := make([]string, len(values))
+		for i, v := range values {
+			t[i] = encode(v)
+		}
+		return t
+	}
+	var del, ins []interface{}
+	flush := func() {
+		n := len(del)
+		if len(ins) < n {
+			n = len(ins)
+		}
+		for i := 0; i < n; i++ {
+			elems = append(elems, compare("", del[i], ins[i]))
+		}
+		for _, v := range del[n:] {
+			elems = append(elems, &Diff{Op: dmp.Delete, Old: v})
+		}
+		for _, v := range ins[n:] {
+			elems = append(elems, &Diff{Op: dmp.Insert, New: v})
+		}
+		del, ins = nil, nil
+	}
+
+	i1, i2 := 0, 0
+	for _, td := range dmp.DiffTokens(tokens(a), tokens(b), 0) {
+		n := len(td.Tokens)
+		switch td.Op {
+		case dmp.Delete:
+			del = append(del, a[i1:i1+n]...)
+			i1 += n
+		case dmp.Insert:
+			ins = append(ins, b[i2:i2+n]...)
+			i2 += n
+		case dmp.Equal:
+			flush()
+			for _, v := range a[i1 : i1+n] {
+				elems = append(elems, &Diff{Op: dmp.Equal, Old: v, New: v})
+			}
+			i1 += n
+			i2 += n
+		}
+	}
+	flush()
+	return
+}
+
+// Return the canonical encoding of a value, with
+// object members sorted by name. The value must have
+// been checked to be encodable, as Compare does.
+func encode(v interface{}) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// An Operation is an element of a JSON Patch.
+type Operation struct {
+	Op    string      `json:"op"` // "add", "remove", or "replace"
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type op Operation
+	return json.Marshal(op(o))
+}
+
+// A Patch is a JSON Patch, as defined by RFC 6902.
+type Patch []Operation
+
+// Return a JSON Patch transforming the old into the new value.
+func (d *Diff) Patch() Patch {
+	var p Patch
+	d.patch("", &p)
+	return p
+}
+
+func (d *Diff) patch(path string, p *Patch) {
+	switch d.Op {
+	case Replace:
+		*p = append(*p, Operation{Op: "replace", Path: path, Value: d.New})
+	case Modify:
+		_, isArray := d.Old.([]interface{})
+		i := 0 // index within the array, as modified so far
+		for _, e := range d.Elems {
+			var ePath string
+			if isArray {
+				ePath = path + "/" + strconv.Itoa(i)
+			} else {
+				ePath = path + "/" + pointerEscaper.Replace(e.Key)
+			}
+			switch e.Op {
+			case dmp.Delete:
+				*p = append(*p, Operation{Op: "remove", Path: ePath})
+				continue
+			case dmp.Insert:
+				*p = append(*p, Operation{Op: "add", Path: ePath, Value: e.New})
+			default:
+				e.patch(ePath, p)
+			}
+			i++
+		}
+	}
+}
+
+// Escape a member name for use in a JSON Pointer (RFC 6901).
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
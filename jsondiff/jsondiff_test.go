@@ -0,0 +1,184 @@
+// Diff Match and Patch – structured JSON diff tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package jsondiff
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+const (
+	doc1 = `{
+	"name": "web",
+	"port": 80,
+	"hosts": ["a", "b", "c", "d"],
+	"limits": {"cpu": 1, "memory": "1Gi"},
+	"title": "The quick brown fox"
+}`
+	doc2 = `{
+	"title": "The slow brown fox",
+	"port": 8080,
+	"hosts": ["a", "c", "d", "e"],
+	"limits": {"cpu": 1, "memory": "2Gi", "disk/tmp": true},
+	"name": "web"
+}`
+)
+
+func TestCompare(t *testing.T) {
+	d, err := CompareJSON([]byte(doc1), []byte(doc1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Op != '=' || len(d.Patch()) != 0 {
+		t.Errorf("Equal: %c %v", d.Op, d.Patch())
+	}
+
+	// Reordered members are not reported.
+	d, err = CompareJSON([]byte(`{"a":1,"b":[1,2]}`), []byte(`{"b":[1,2],"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Op != '=' {
+		t.Errorf("Reordered: %c", d.Op)
+	}
+
+	d, err = CompareJSON([]byte(doc1), []byte(doc2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[` +
+		`{"op":"remove","path":"/hosts/1"},` +
+		`{"op":"add","path":"/hosts/3","value":"e"},` +
+		`{"op":"add","path":"/limits/disk~1tmp","value":true},` +
+		`{"op":"replace","path":"/limits/memory","value":"2Gi"},` +
+		`{"op":"replace","path":"/port","value":8080},` +
+		`{"op":"replace","path":"/title","value":"The slow brown fox"}]`
+	assertJSON(t, "Patch", want, d.Patch())
+
+	for _, v := range []interface{}{math.NaN(), math.Inf(1), make(chan int), func() {}, []interface{}{1, math.NaN()}} {
+		if _, err := Compare(1.0, v); err == nil {
+			t.Errorf("%T: invalid value not rejected", v)
+		}
+	}
+
+	for _, doc := range []string{`{"a":1} garbage`, `{"a":1} {"a":1}`, `1 2`} {
+		if _, err := CompareJSON([]byte(doc), []byte(`{"a":1}`)); err != ErrTrailingData {
+			t.Errorf("%s: trailing data: %v", doc, err)
+		}
+	}
+	if _, err := CompareJSON([]byte("{\"a\":1}\n\t "), []byte(`{"a":1}`)); err != nil {
+		t.Errorf("trailing white space: %v", err)
+	}
+
+	wantText := `  {
+    "hosts": [
+      "a",
+-     "b",
+      "c",
+      "d",
++     "e"
+    ],
+    "limits": {
+      "cpu": 1,
++     "disk/tmp": true,
+~     "memory": "[-1-]{+2+}Gi"
+    },
+    "name": "web",
+-   "port": 80,
++   "port": 8080,
+~   "title": "The [-quick-]{+slow+} brown fox"
+  }
+`
+	if s := d.String(); s != wantText {
+		t.Errorf("String:\n%s\nwant:\n%s", s, wantText)
+	}
+}
+
+func TestCompareArrays(t *testing.T) {
+	for _, x := range []struct {
+		a, b  string
+		patch string
+	}{
+		{`[1,2,3]`, `[1,2,3,4]`, `[{"op":"add","path":"/3","value":4}]`},
+		{`[1,2,3]`, `[0,1,2,3]`, `[{"op":"add","path":"/0","value":0}]`},
+		{`[1,2,3]`, `[1,5,3]`, `[{"op":"replace","path":"/1","value":5}]`},
+		{`[1,2,3]`, `[3]`, `[{"op":"remove","path":"/0"},{"op":"remove","path":"/0"}]`},
+		{`[{"id":1,"v":"x"},{"id":2}]`, `[{"id":1,"v":"y"},{"id":2}]`, `[{"op":"replace","path":"/0/v","value":"y"}]`},
+		{`[1,[2,3]]`, `["1",[2,4]]`, `[{"op":"replace","path":"/0","value":"1"},{"op":"replace","path":"/1/1","value":4}]`},
+		{`{"a":[1]}`, `{"a":{"0":1}}`, `[{"op":"replace","path":"/a","value":{"0":1}}]`},
+		{`1`, `null`, `[{"op":"replace","path":"","value":null}]`},
+	} {
+		d, err := CompareJSON([]byte(x.a), []byte(x.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertJSON(t, x.a+" -> "+x.b, x.patch, d.Patch())
+	}
+}
+
+func TestPatchApply(t *testing.T) {
+	docs := []string{
+		doc1, doc2,
+		`[]`, `[1,2,3]`, `[3,2,1]`, `[[1],[2,[3]],{"a":[]}]`, `[[2,[3,4]],{"a":[5]},6]`,
+		`{"a/b":{"c~d":[1,{"e":null}]}}`, `{"a/b":{"c~d":[{"e":false},2]},"f":"g"}`,
+		`"text"`, `null`,
+	}
+	for _, a := range docs {
+		for _, b := range docs {
+			va, _ := decode([]byte(a))
+			vb, _ := decode([]byte(b))
+			d, err := Compare(va, vb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := d.Patch()
+			v, err := p.Apply(va)
+			if err != nil {
+				t.Errorf("%s -> %s: %v", a, b, err)
+				continue
+			}
+			if encode(v) != encode(vb) {
+				t.Errorf("%s -> %s: got %s", a, b, encode(v))
+			}
+			if s, _ := decode([]byte(a)); encode(s) != encode(va) {
+				t.Errorf("%s: modified by Apply", a)
+			}
+		}
+	}
+
+	doc, _ := decode([]byte(`{"a":[1,2]}`))
+	for _, x := range []struct {
+		patch string
+		err   error
+	}{
+		{`[{"op":"add","path":"/a/-","value":3},{"op":"test","path":"/a/2","value":3}]`, nil},
+		{`[{"op":"test","path":"/a/0","value":2}]`, ErrTest},
+		{`[{"op":"remove","path":"/a/2"}]`, ErrPath},
+		{`[{"op":"replace","path":"/b","value":1}]`, ErrPath},
+		{`[{"op":"add","path":"a","value":1}]`, ErrPath},
+		{`[{"op":"move","from":"/a","path":"/b"}]`, ErrOperation},
+	} {
+		var p Patch
+		if err := json.Unmarshal([]byte(x.patch), &p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Apply(doc); err != x.err {
+			t.Errorf("%s: error %v, want %v", x.patch, err, x.err)
+		}
+	}
+}
+
+func assertJSON(t *testing.T, descr, want string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("%s:\n have %s\n want %s", descr, b, want)
+	}
+}
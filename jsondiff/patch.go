@@ -0,0 +1,147 @@
+// Diff Match and Patch – application of JSON Patches
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package jsondiff
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPath      = errors.New("jsondiff: path not found")
+	ErrOperation = errors.New("jsondiff: unsupported operation")
+	ErrTest      = errors.New("jsondiff: test failed")
+)
+
+// Apply the patch to a decoded JSON value, and return the result.
+// Supported operations are "add", "remove", "replace", and "test".
+// The value passed is not modified.
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	doc = clone(doc)
+	for i := range p {
+		o := &p[i]
+		switch o.Op {
+		case "add", "remove", "replace", "test":
+		default:
+			return nil, ErrOperation
+		}
+		if o.Path == "" {
+			switch o.Op {
+			case "remove":
+				return nil, ErrPath
+			case "test":
+				if encode(doc) != encode(o.Value) {
+					return nil, ErrTest
+				}
+			default:
+				doc = clone(o.Value)
+			}
+			continue
+		}
+		if !strings.HasPrefix(o.Path, "/") {
+			return nil, ErrPath
+		}
+		var tokens []string
+		for _, t := range strings.Split(o.Path[1:], "/") {
+			tokens = append(tokens, pointerUnescaper.Replace(t))
+		}
+		var err error
+		if doc, err = o.apply(doc, tokens); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Apply the operation to the value located by tokens within v,
+// and return the modified v.
+func (o *Operation) apply(v interface{}, tokens []string) (interface{}, error) {
+	key := tokens[0]
+	last := len(tokens) == 1
+	switch v := v.(type) {
+	case map[string]interface{}:
+		child, ok := v[key]
+		if !ok && !(last && o.Op == "add") {
+			return nil, ErrPath
+		}
+		if !last {
+			child, err := o.apply(child, tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			v[key] = child
+			return v, nil
+		}
+		switch o.Op {
+		case "add", "replace":
+			v[key] = clone(o.Value)
+		case "remove":
+			delete(v, key)
+		case "test":
+			if encode(child) != encode(o.Value) {
+				return nil, ErrTest
+			}
+		}
+		return v, nil
+
+	case []interface{}:
+		n := len(v)
+		if key == "-" {
+			key = strconv.Itoa(n)
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > n || i == n && !(last && o.Op == "add") {
+			return nil, ErrPath
+		}
+		if !last {
+			child, err := o.apply(v[i], tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = child
+			return v, nil
+		}
+		switch o.Op {
+		case "add":
+			v = append(v, nil)
+			copy(v[i+1:], v[i:])
+			v[i] = clone(o.Value)
+		case "replace":
+			v[i] = clone(o.Value)
+		case "remove":
+			v = append(v[:i], v[i+1:]...)
+		case "test":
+			if encode(v[i]) != encode(o.Value) {
+				return nil, ErrTest
+			}
+		}
+		return v, nil
+	}
+	return nil, ErrPath
+}
+
+// Return a deep copy of a decoded JSON value.
+func clone(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = clone(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = clone(e)
+		}
+		return a
+	}
+	return v
+}
@@ -0,0 +1,228 @@
+// Diff Match and Patch – conversion to LSP text edits
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package lsp converts Diff lists into the text edits of the Language
+Server Protocol, and applies lists of such edits to a text.
+
+Positions consist of a zero-based line and a character offset within
+that line. The unit of the character offset is selected by a
+PositionEncoding, as negotiated between client and server since
+LSP 3.17; UTF-16 is the protocol's default.
+*/
+package lsp
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/knieriem/dmp"
+)
+
+type PositionEncoding string
+
+const (
+	UTF8  PositionEncoding = "utf-8"
+	UTF16 PositionEncoding = "utf-16"
+	UTF32 PositionEncoding = "utf-32"
+)
+
+// Return the number of units needed to encode r.
+func (enc PositionEncoding) width(r rune) int {
+	switch enc {
+	case UTF8:
+		return utf8.RuneLen(r)
+	case UTF32:
+		return 1
+	}
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// A cursor tracks the position at the end of the text it has been
+// advanced over. As in LSP, "\n", "\r\n" and "\r" terminate lines.
+type cursor struct {
+	Position
+	enc PositionEncoding
+	cr  bool
+}
+
+func (c *cursor) advance(text string) {
+	for _, r := range text {
+		switch {
+		case r == '\n' && c.cr:
+			// second half of a "\r\n" sequence
+		case r == '\n' || r == '\r':
+			c.Line++
+			c.Character = 0
+		default:
+			c.Character += c.enc.width(r)
+		}
+		c.cr = r == '\r'
+	}
+}
+
+// TextEdits converts a Diff list into a minimal list of text edits
+// that transform text1 into text2. Each run of deletions and insertions
+// between two equalities results in a single edit. As a position
+// cannot refer to the middle of a "\r\n" sequence, edits starting
+// or ending there are extended to cover the whole sequence.
+func TextEdits(diffs dmp.Diffs, enc PositionEncoding) (edits []TextEdit) {
+	type span struct {
+		start, end int // byte offsets into text1
+		newText    string
+	}
+	var spans []span
+	text1 := diffs.Text1()
+	pos := 0
+	var s *span
+	for _, d := range diffs {
+		if d.Op == dmp.Equal {
+			s = nil
+			pos += len(d.Text)
+			continue
+		}
+		if s == nil {
+			spans = append(spans, span{start: pos, end: pos})
+			s = &spans[len(spans)-1]
+		}
+		switch d.Op {
+		case dmp.Delete:
+			pos += len(d.Text)
+			s.end = pos
+		case dmp.Insert:
+			s.newText += d.Text
+		}
+	}
+
+	splitsCRLF := func(i int) bool {
+		return i > 0 && i < len(text1) && text1[i-1] == '\r' && text1[i] == '\n'
+	}
+	c := cursor{enc: enc}
+	pos = 0
+	for _, s := range spans {
+		if splitsCRLF(s.start) {
+			s.start--
+			s.newText = "\r" + s.newText
+		}
+		if splitsCRLF(s.end) {
+			s.end++
+			s.newText += "\n"
+		}
+		if n := len(edits); n != 0 && s.start == pos {
+			// The edit touches the previous one.
+			e := &edits[n-1]
+			e.NewText += s.newText
+			c.advance(text1[pos:s.end])
+			e.Range.End = c.Position
+			pos = s.end
+			continue
+		}
+		c.advance(text1[pos:s.start])
+		start := c.Position
+		c.advance(text1[s.start:s.end])
+		edits = append(edits, TextEdit{Range{start, c.Position}, s.newText})
+		pos = s.end
+	}
+	return
+}
+
+var (
+	ErrPosition = errors.New("lsp: position outside of text")
+	ErrOverlap  = errors.New("lsp: overlapping text edits")
+)
+
+// Apply applies a list of text edits to text. As in LSP, all ranges refer
+// to the original text; edits starting at the same position are applied in
+// the order of the list. A character offset beyond the end of a line
+// refers to the end of that line.
+func Apply(text string, edits []TextEdit, enc PositionEncoding) (string, error) {
+	lines := lineStarts(text)
+	type span struct {
+		start, end int
+		newText    string
+	}
+	spans := make([]span, len(edits))
+	for i, e := range edits {
+		start, ok1 := offset(text, lines, e.Range.Start, enc)
+		end, ok2 := offset(text, lines, e.Range.End, enc)
+		if !ok1 || !ok2 || end < start {
+			return "", ErrPosition
+		}
+		spans[i] = span{start, end, e.NewText}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans {
+		if s.start < pos {
+			return "", ErrOverlap
+		}
+		b.WriteString(text[pos:s.start])
+		b.WriteString(s.newText)
+		pos = s.end
+	}
+	b.WriteString(text[pos:])
+	return b.String(), nil
+}
+
+// Return the byte offsets of the starts of all lines of text.
+func lineStarts(text string) []int {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			fallthrough
+		case '\n':
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// Convert a position into a byte offset of text.
+func offset(text string, lines []int, p Position, enc PositionEncoding) (int, bool) {
+	if p.Line < 0 || p.Character < 0 {
+		return 0, false
+	}
+	if p.Line >= len(lines) {
+		return 0, false
+	}
+	start := lines[p.Line]
+	n := p.Character
+	for i, r := range text[start:] {
+		if n <= 0 || r == '\n' || r == '\r' {
+			return start + i, true
+		}
+		n -= enc.width(r)
+	}
+	return len(text), true
+}
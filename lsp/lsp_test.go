@@ -0,0 +1,81 @@
+// Diff Match and Patch – LSP conversion tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/knieriem/dmp"
+)
+
+func TestTextEdits(t *testing.T) {
+	diffs := dmp.Diffs{{Op: dmp.Equal, Text: "a😀b\n"}, {Op: dmp.Delete, Text: "x"}, {Op: dmp.Insert, Text: "y"}, {Op: dmp.Equal, Text: "z"}, {Op: dmp.Insert, Text: "\n"}}
+	for _, x := range []struct {
+		enc  PositionEncoding
+		want string
+	}{
+		{UTF8, "[{{{1 0} {1 1}} y} {{{1 2} {1 2}} \n}]"},
+		{UTF16, "[{{{1 0} {1 1}} y} {{{1 2} {1 2}} \n}]"},
+		{UTF32, "[{{{1 0} {1 1}} y} {{{1 2} {1 2}} \n}]"},
+	} {
+		if have := fmt.Sprint(TextEdits(diffs, x.enc)); have != x.want {
+			t.Errorf("%s: want %q, have %q", x.enc, x.want, have)
+		}
+	}
+
+	diffs = dmp.Diffs{{Op: dmp.Equal, Text: "a😀"}, {Op: dmp.Delete, Text: "b"}, {Op: dmp.Insert, Text: "c"}}
+	for _, x := range []struct {
+		enc  PositionEncoding
+		want string
+	}{
+		{UTF8, "[{{{0 5} {0 6}} c}]"},
+		{UTF16, "[{{{0 3} {0 4}} c}]"},
+		{UTF32, "[{{{0 2} {0 3}} c}]"},
+	} {
+		if have := fmt.Sprint(TextEdits(diffs, x.enc)); have != x.want {
+			t.Errorf("%s: want %q, have %q", x.enc, x.want, have)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	pairs := [][2]string{
+		{"", "abc"},
+		{"abc", ""},
+		{"func f() {\r\n\treturn 1\r\n}\r\n", "func f() int {\r\n\treturn 1 // 😀\r\n}\r\n"},
+		{"Käse\nBrot\n", "Kase\nBrötchen\nund 😀\n"},
+		{"a\rb\rc", "a\rB\rc\r"},
+		{"a\r\nb\r\nc\r\n", "a\nb\nc\n"},
+		{"a\nb\nc\n", "a\r\nb\r\nc\r\n"},
+		{"a\r\nb", "a\rX\nb"},
+		{"x\r\ny", "x\r\r\ny"},
+		{"x\r\ny", "x\ry"},
+	}
+	for _, p := range pairs {
+		diffs := dmp.DiffMain(p[0], p[1], false, time.Second)
+		for _, enc := range []PositionEncoding{UTF8, UTF16, UTF32} {
+			edits := TextEdits(diffs, enc)
+			have, err := Apply(p[0], edits, enc)
+			if err != nil || have != p[1] {
+				t.Errorf("%s: %q -> %q: have %q, %v", enc, p[0], p[1], have, err)
+			}
+		}
+	}
+
+	r := Range{Position{0, 1}, Position{0, 3}}
+	if _, err := Apply("abcd", []TextEdit{{r, "x"}, {Range{Position{0, 2}, Position{0, 2}}, "y"}}, UTF16); err != ErrOverlap {
+		t.Error("overlap not detected:", err)
+	}
+	if _, err := Apply("abcd", []TextEdit{{Range{Position{2, 0}, Position{2, 0}}, "y"}}, UTF16); err != ErrPosition {
+		t.Error("invalid position not detected:", err)
+	}
+	if have, _ := Apply("ab\ncd", []TextEdit{{Range{Position{0, 9}, Position{1, 0}}, "-"}}, UTF16); have != "ab-cd" {
+		t.Error("character beyond line end not clamped:", have)
+	}
+}
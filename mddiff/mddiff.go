@@ -0,0 +1,408 @@
+// Diff Match and Patch – Markdown-aware diffs
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+/*
+Package mddiff compares Markdown documents, and renders the changes
+as Markdown, with deleted and inserted text marked up.
+
+The documents are split into blocks – headings, list items, code
+fences, and paragraphs –, which are aligned first. Blocks of the same
+kind that have been changed are then compared word by word, and the
+changed words marked; a change of the level of a heading is marked
+as a substitution of its marker. Changed code fences are rendered
+as fences of the language "diff", listing deleted and inserted lines;
+the original info string follows "diff".
+*/
+package mddiff
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/knieriem/dmp"
+)
+
+// A Style selects the markup of changes.
+type Style int
+
+const (
+	Strike       Style = iota // ~~deleted~~ and **inserted**
+	CriticMarkup              // {--deleted--}, {++inserted++}, and {~~old~>new~~}
+)
+
+// Compare two Markdown texts, and return the second one, with
+// the changes marked up.
+func Diff(md1, md2 string, style Style) string {
+	blocks1, _ := parse(md1)
+	blocks2, tail := parse(md2)
+	keys := func(blocks []*block) []string {
+		k := make([]string, len(blocks))
+		for i, b := range blocks {
+			k[i] = string(rune('0'+b.kind)) + b.prefix + b.text + b.close
+		}
+		return k
+	}
+
+	r := &renderer{style: style}
+	i1, i2 := 0, 0
+	for _, td := range dmp.DiffTokens(keys(blocks1), keys(blocks2), 0) {
+		n := len(td.Tokens)
+		switch td.Op {
+		case dmp.Equal:
+			r.flush()
+			for _, b := range blocks2[i2 : i2+n] {
+				r.WriteString(b.sep + b.prefix + b.text + b.close)
+			}
+			i1 += n
+			i2 += n
+		case dmp.Delete:
+			r.del = append(r.del, blocks1[i1:i1+n]...)
+			i1 += n
+		case dmp.Insert:
+			r.ins = append(r.ins, blocks2[i2:i2+n]...)
+			i2 += n
+		}
+	}
+	r.flush()
+	r.WriteString(tail)
+	return r.String()
+}
+
+const (
+	paragraph = iota
+	heading
+	item
+	fence
+)
+
+// A block of a Markdown document.
+type block struct {
+	kind   int
+	sep    string // blank lines preceding the block
+	prefix string // heading or list item marker, or the opening line of a fence
+	text   string // content, including line breaks
+	close  string // closing line of a fence
+	marker string // of a fence, e.g. "```"
+}
+
+var (
+	headingPrefix = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+|$)`)
+	itemPrefix    = regexp.MustCompile(`^[ \t]*(?:[-*+]|[0-9]{1,9}[.)])(?:[ \t]+|$)`)
+	fencePrefix   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Split a Markdown text into blocks. Blank lines at the end
+// are returned as tail.
+func parse(md string) (blocks []*block, tail string) {
+	lines := strings.SplitAfter(md, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	startsBlock := func(line string) bool {
+		return headingPrefix.MatchString(line) || itemPrefix.MatchString(line) || fencePrefix.MatchString(line)
+	}
+	sep := ""
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			sep += line
+			i++
+			continue
+		}
+		b := &block{sep: sep}
+		sep = ""
+		blocks = append(blocks, b)
+		i++
+		if m := fencePrefix.FindStringSubmatch(line); m != nil {
+			b.kind = fence
+			b.prefix = line
+			b.marker = m[1]
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimLeft(lines[i], " "), b.marker) {
+					b.close = lines[i]
+					i++
+					break
+				}
+				b.text += lines[i]
+			}
+			continue
+		}
+		if m := headingPrefix.FindString(line); m != "" {
+			b.kind = heading
+			b.prefix = m
+			b.text = line[len(m):]
+			continue
+		}
+		if m := itemPrefix.FindString(line); m != "" {
+			b.kind = item
+			b.prefix = m
+			b.text = line[len(m):]
+		} else {
+			b.text = line
+		}
+		// continuation lines
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) || startsBlock(line) || b.kind == item && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				break
+			}
+			b.text += line
+		}
+	}
+	return blocks, sep
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+type renderer struct {
+	strings.Builder
+	style    Style
+	del, ins []*block // pending changed blocks
+}
+
+// Render the pending deleted and inserted blocks. Blocks of the same
+// kind are compared, if they are similar enough.
+func (r *renderer) flush() {
+	del, ins := r.del, r.ins
+	r.del, r.ins = nil, nil
+	hasKind := func(blocks []*block, kind int) bool {
+		for _, b := range blocks {
+			if b.kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+	for len(del) != 0 || len(ins) != 0 {
+		switch {
+		case len(del) != 0 && len(ins) != 0 && del[0].kind == ins[0].kind:
+			if !r.changed(del[0], ins[0]) {
+				r.deleted(del[0])
+				r.inserted(ins[0])
+			}
+			del, ins = del[1:], ins[1:]
+		case len(del) != 0 && (len(ins) == 0 || !hasKind(ins, del[0].kind)):
+			r.deleted(del[0])
+			del = del[1:]
+		default:
+			r.inserted(ins[0])
+			ins = ins[1:]
+		}
+	}
+}
+
+func (r *renderer) deleted(b *block) {
+	if b.kind == fence {
+		r.fence(b, b.text, "")
+		return
+	}
+	r.WriteString(b.sep + b.prefix)
+	r.mark(dmp.Delete, b.text)
+}
+
+func (r *renderer) inserted(b *block) {
+	if b.kind == fence {
+		r.fence(b, "", b.text)
+		return
+	}
+	r.WriteString(b.sep + b.prefix)
+	r.mark(dmp.Insert, b.text)
+}
+
+// Render a block b2 that replaces b1, if they are similar,
+// and report whether they are.
+func (r *renderer) changed(b1, b2 *block) bool {
+	if b1.kind == fence {
+		r.fence(b2, b1.text, b2.text)
+		return true
+	}
+	diffs := wordDiff(b1.text, b2.text)
+	if !similar(diffs) {
+		return false
+	}
+	r.WriteString(b2.sep + b2.prefix)
+	if m1, m2 := strings.TrimSpace(b1.prefix), strings.TrimSpace(b2.prefix); b1.kind == heading && m1 != m2 {
+		if r.style != CriticMarkup || !r.substitute(m1, m2) {
+			r.mark(dmp.Delete, m1)
+			r.WriteString(" ")
+			r.mark(dmp.Insert, m2)
+		}
+		r.WriteString(" ")
+	}
+	for i := 0; i < len(diffs); i++ {
+		d := diffs[i]
+		if d.Op == dmp.Equal {
+			r.WriteString(d.Text)
+			continue
+		}
+		if r.style == CriticMarkup && d.Op == dmp.Delete && i+1 < len(diffs) && diffs[i+1].Op == dmp.Insert {
+			if r.substitute(d.Text, diffs[i+1].Text) {
+				i++
+				continue
+			}
+		}
+		r.mark(d.Op, d.Text)
+	}
+	return true
+}
+
+// Two texts are similar if at least half of the words
+// of the shorter one are unchanged.
+func similar(diffs dmp.Diffs) bool {
+	var n [3]int // words of text1, text2, and both
+	for _, d := range diffs {
+		k := len(strings.Fields(d.Text))
+		switch d.Op {
+		case dmp.Delete:
+			n[0] += k
+		case dmp.Insert:
+			n[1] += k
+		case dmp.Equal:
+			n[0] += k
+			n[1] += k
+			n[2] += k
+		}
+	}
+	shorter := n[0]
+	if n[1] < shorter {
+		shorter = n[1]
+	}
+	return 2*n[2] >= shorter
+}
+
+// Compare two texts word by word. White space between changed
+// words is made part of the changes.
+func wordDiff(text1, text2 string) dmp.Diffs {
+	diffs := dmp.DiffTokens(words(text1), words(text2), 0).Diffs()
+	var (
+		out      dmp.Diffs
+		del, ins string
+	)
+	flush := func() {
+		if del != "" {
+			out = append(out, dmp.Diff{Op: dmp.Delete, Text: del})
+		}
+		if ins != "" {
+			out = append(out, dmp.Diff{Op: dmp.Insert, Text: ins})
+		}
+		del, ins = "", ""
+	}
+	for i, d := range diffs {
+		switch d.Op {
+		case dmp.Delete:
+			del += d.Text
+		case dmp.Insert:
+			ins += d.Text
+		case dmp.Equal:
+			if (del != "" || ins != "") && i+1 < len(diffs) && isBlank(d.Text) {
+				del += d.Text
+				ins += d.Text
+				continue
+			}
+			flush()
+			out = append(out, d)
+		}
+	}
+	flush()
+	return out
+}
+
+// Split a text into words, and runs of white space.
+func words(text string) (w []string) {
+	for text != "" {
+		n := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+		if n == 0 {
+			n = strings.IndexFunc(text, unicode.IsSpace)
+			if n == -1 {
+				n = len(text)
+			}
+		}
+		w = append(w, text[:n])
+		text = text[n:]
+	}
+	return
+}
+
+// Mark deleted or inserted text. White space at its edges is kept
+// outside of the markup, where emphasis could not start or end.
+// Deletions consisting of white space only are left out, insertions
+// are written without markup.
+func (r *renderer) mark(op int, text string) {
+	lead, core, trail := splitSpace(text)
+	if core == "" {
+		if op == dmp.Insert {
+			r.WriteString(text)
+		}
+		return
+	}
+	r.WriteString(lead)
+	switch {
+	case op == dmp.Delete && r.style == CriticMarkup:
+		r.WriteString("{--" + core + "--}")
+	case op == dmp.Delete:
+		r.WriteString("~~" + core + "~~")
+	case r.style == CriticMarkup:
+		r.WriteString("{++" + core + "++}")
+	default:
+		r.WriteString("**" + core + "**")
+	}
+	r.WriteString(trail)
+}
+
+// Write a CriticMarkup substitution, and report whether it has been
+// possible; it is not if either text consists of white space only.
+func (r *renderer) substitute(old, new string) bool {
+	_, core1, _ := splitSpace(old)
+	lead, core2, trail := splitSpace(new)
+	if core1 == "" || core2 == "" {
+		return false
+	}
+	r.WriteString(lead + "{~~" + core1 + "~>" + core2 + "~~}" + trail)
+	return true
+}
+
+func splitSpace(text string) (lead, core, trail string) {
+	core = strings.TrimLeftFunc(text, unicode.IsSpace)
+	lead = text[:len(text)-len(core)]
+	core = strings.TrimRightFunc(core, unicode.IsSpace)
+	trail = text[len(lead)+len(core):]
+	return
+}
+
+// Render a fence of the language "diff", showing the changes
+// between the lines of text1 and text2. The info string of b,
+// e.g. the language of the code, is kept after "diff".
+func (r *renderer) fence(b *block, text1, text2 string) {
+	info := strings.TrimSpace(strings.TrimLeft(b.prefix, " ")[len(b.marker):])
+	if info != "" {
+		info = " " + info
+	}
+	r.WriteString(b.sep + b.marker + "diff" + info + "\n")
+	for _, td := range dmp.DiffTokens(lines(text1), lines(text2), 0) {
+		prefix := string(rune(td.Op))
+		if td.Op == dmp.Equal {
+			prefix = " "
+		}
+		for _, line := range td.Tokens {
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			r.WriteString(prefix + line)
+		}
+	}
+	r.WriteString(b.marker + "\n")
+}
+
+func lines(text string) []string {
+	l := strings.SplitAfter(text, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
@@ -0,0 +1,126 @@
+// Diff Match and Patch – Markdown-aware diff tests
+// 	Go port:	Copyright 2012 M. Teichgräber
+//
+// Use of this source code is governed by the Apache License,
+// Version 2.0, that can be found in the LICENSE file.
+
+package mddiff
+
+import (
+	"testing"
+)
+
+const md1 = "# Introduction\n" +
+	"\n" +
+	"The quick brown fox jumps over\n" +
+	"the lazy dog.\n" +
+	"\n" +
+	"- first item\n" +
+	"- second item\n" +
+	"  continued\n" +
+	"- third item\n" +
+	"\n" +
+	"```go\n" +
+	"x := 1\n" +
+	"y := 2\n" +
+	"```\n" +
+	"\n" +
+	"Obsolete paragraph.\n"
+
+const md2 = "# Introduction\n" +
+	"\n" +
+	"The slow red fox jumps over\n" +
+	"the lazy dog.\n" +
+	"\n" +
+	"- first item\n" +
+	"- second item\n" +
+	"  continued here\n" +
+	"- a new item\n" +
+	"- third item\n" +
+	"\n" +
+	"```go\n" +
+	"x := 1\n" +
+	"y := 3\n" +
+	"```\n" +
+	"\n" +
+	"## Summary\n"
+
+func TestDiff(t *testing.T) {
+	want := "# Introduction\n" +
+		"\n" +
+		"The ~~quick brown~~**slow red** fox jumps over\n" +
+		"the lazy dog.\n" +
+		"\n" +
+		"- first item\n" +
+		"- second item\n" +
+		"  continued **here**\n" +
+		"- **a new item**\n" +
+		"- third item\n" +
+		"\n" +
+		"```diff go\n" +
+		" x := 1\n" +
+		"-y := 2\n" +
+		"+y := 3\n" +
+		"```\n" +
+		"\n" +
+		"~~Obsolete paragraph.~~\n" +
+		"\n" +
+		"## **Summary**\n"
+	if have := Diff(md1, md2, Strike); have != want {
+		t.Errorf("Strike:\n%s\nwant:\n%s", have, want)
+	}
+
+	want = "# Introduction\n" +
+		"\n" +
+		"The {~~quick brown~>slow red~~} fox jumps over\n" +
+		"the lazy dog.\n" +
+		"\n" +
+		"- first item\n" +
+		"- second item\n" +
+		"  continued {++here++}\n" +
+		"- {++a new item++}\n" +
+		"- third item\n" +
+		"\n" +
+		"```diff go\n" +
+		" x := 1\n" +
+		"-y := 2\n" +
+		"+y := 3\n" +
+		"```\n" +
+		"\n" +
+		"{--Obsolete paragraph.--}\n" +
+		"\n" +
+		"## {++Summary++}\n"
+	if have := Diff(md1, md2, CriticMarkup); have != want {
+		t.Errorf("CriticMarkup:\n%s\nwant:\n%s", have, want)
+	}
+
+	if have := Diff(md1, md1, Strike); have != md1 {
+		t.Errorf("Equal:\n%s", have)
+	}
+}
+
+func TestDiffHeadingLevel(t *testing.T) {
+	md1, md2 := "# Title\n", "## Title\n"
+	if have, want := Diff(md1, md2, Strike), "## ~~#~~ **##** Title\n"; have != want {
+		t.Errorf("Strike: have %q, want %q", have, want)
+	}
+	if have, want := Diff(md1, md2, CriticMarkup), "## {~~#~>##~~} Title\n"; have != want {
+		t.Errorf("CriticMarkup: have %q, want %q", have, want)
+	}
+}
+
+func TestDiffFenceInfo(t *testing.T) {
+	have := Diff("~~~ python\nx = 1\n~~~\n", "~~~ python\nx = 2\n~~~\n", Strike)
+	want := "~~~diff python\n-x = 1\n+x = 2\n~~~\n"
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestDiffDissimilar(t *testing.T) {
+	have := Diff("Alpha beta gamma.\n", "Delta epsilon.\n", Strike)
+	want := "~~Alpha beta gamma.~~\n**Delta epsilon.**\n"
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}